@@ -0,0 +1,115 @@
+// Package anonymize replaces the usernames, table names and hand IDs of hands with aliases so they can be shared.
+//
+// Aliases are derived from an HMAC of the original value under a secret key, so the same player, table or hand
+// gets the same alias in every hand anonymised with that key, across batches and runs, while the original values
+// cannot be recovered without the key.
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"pokerhud/hands"
+	"strconv"
+)
+
+// ErrNoKey is returned by New when the key is empty, which would make aliases trivial to reverse.
+var ErrNoKey = errors.New("error an anonymization key is required")
+
+// handIDDigits is the number of digits in an anonymised hand ID, matching the length of PokerStars hand IDs.
+const handIDDigits = 12
+
+// Options configure an Anonymizer.
+type Options struct {
+	// KeepHero leaves the username of the hero, the player the hand histories belong to, unchanged.
+	KeepHero bool
+}
+
+// Anonymizer replaces identifying values in hands with keyed aliases.
+type Anonymizer struct {
+	key  []byte
+	opts Options
+}
+
+// New returns an Anonymizer deriving aliases from key.
+func New(key []byte, opts Options) (*Anonymizer, error) {
+	if len(key) == 0 {
+		return nil, ErrNoKey
+	}
+	return &Anonymizer{key: key, opts: opts}, nil
+}
+
+// Hand returns a copy of h with every username, the table name and the hand ID replaced by their aliases. The
+// warnings of h are dropped, as they quote the original lines, file and hand ID.
+func (a *Anonymizer) Hand(h hands.Hand) hands.Hand {
+	hero := h.Metadata.Hero
+	name := func(username string) string {
+		if username == "" || a.opts.KeepHero && username == hero {
+			return username
+		}
+		return a.Username(username)
+	}
+
+	anon := h
+	anon.Metadata.ID = a.HandID(h.Metadata.ID)
+	anon.Metadata.Table = a.Table(h.Metadata.Table)
+	anon.Metadata.Hero = name(h.Metadata.Hero)
+	anon.Warnings = nil
+
+	anon.Players = make([]hands.Player, len(h.Players))
+	for i, p := range h.Players {
+		p.Username = name(p.Username)
+		anon.Players[i] = p
+	}
+
+	anon.Actions = make([]hands.Action, len(h.Actions))
+	for i, act := range h.Actions {
+		act.PlayerName = name(act.PlayerName)
+		anon.Actions[i] = act
+	}
+
+	if h.Summary.Winners != nil {
+		anon.Summary.Winners = make([]hands.Winner, len(h.Summary.Winners))
+		for i, w := range h.Summary.Winners {
+			w.PlayerName = name(w.PlayerName)
+			anon.Summary.Winners[i] = w
+		}
+	}
+
+	return anon
+}
+
+// Username returns the alias of a username, e.g. "player_3f9a0c12".
+func (a *Anonymizer) Username(username string) string {
+	return "player_" + hex.EncodeToString(a.mac("username", username)[:4])
+}
+
+// Table returns the alias of a table name, e.g. "Table 3f9a0c". An empty table name stays empty.
+func (a *Anonymizer) Table(table string) string {
+	if table == "" {
+		return ""
+	}
+	return "Table " + hex.EncodeToString(a.mac("table", table)[:3])
+}
+
+// HandID returns the alias of a hand ID, a number with the same number of digits as a PokerStars hand ID.
+func (a *Anonymizer) HandID(id string) string {
+	n := binary.BigEndian.Uint64(a.mac("hand", id)[:8])
+	low := uint64(1)
+	for range handIDDigits - 1 {
+		low *= 10
+	}
+	return strconv.FormatUint(low+n%(9*low), 10)
+}
+
+// mac returns the HMAC of value under the key, separated by kind so that e.g. a username and a table with the same
+// name get unrelated aliases.
+func (a *Anonymizer) mac(kind, value string) []byte {
+	m := hmac.New(sha256.New, a.key)
+	m.Write([]byte(kind))
+	m.Write([]byte{0})
+	m.Write([]byte(value))
+	return m.Sum(nil)
+}
@@ -0,0 +1,164 @@
+package anonymize
+
+import (
+	"bytes"
+	"errors"
+	"pokerhud/export"
+	"pokerhud/hands"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testHand = hands.Hand{
+	Metadata: hands.Metadata{
+		ID:         "254446123323",
+		Date:       time.Date(2025, time.January, 19, 12, 38, 55, 0, time.UTC),
+		ButtonSeat: 1,
+		Site:       hands.SitePokerStars,
+		Table:      "Wei III",
+		Hero:       "KavarzE",
+	},
+	Players: []hands.Player{
+		{Username: "maximoIV", Seat: 1, ChipCount: 5.2},
+		{Username: "KavarzE", Cards: [2]hands.Card{"2s", "5d"}, Seat: 3, ChipCount: 5},
+		{Username: "pernadao1599", Cards: [2]hands.Card{"Jh", "Qc"}, Seat: 6, ChipCount: 3.43},
+	},
+	Actions: []hands.Action{
+		{PlayerName: "KavarzE", Order: 1, Street: hands.Preflop, ActionType: hands.ActionPost, Amount: 0.05},
+		{PlayerName: "pernadao1599", Order: 2, Street: hands.Preflop, ActionType: hands.ActionCall, Amount: 0.05},
+		{PlayerName: "maximoIV", Order: 3, Street: hands.Preflop, ActionType: hands.ActionFold},
+		{PlayerName: "KavarzE", Order: 4, Street: hands.Preflop, ActionType: hands.ActionCheck},
+	},
+	Summary: hands.Summary{
+		Pot:     0.1,
+		Winners: []hands.Winner{{PlayerName: "pernadao1599", Amount: 0.1, Board: 1}},
+	},
+}
+
+func TestAnonymizerHand(t *testing.T) {
+	a, _ := New([]byte("study group"), Options{})
+	got := a.Hand(testHand)
+
+	t.Run("identifying values are replaced consistently", func(t *testing.T) {
+		kavarze := a.Username("KavarzE")
+		if got.Players[1].Username != kavarze || got.Actions[0].PlayerName != kavarze || got.Actions[3].PlayerName != kavarze {
+			t.Errorf("wanted every KavarzE replaced by %q but got %#v", kavarze, got)
+		}
+
+		if got.Metadata.Hero != kavarze {
+			t.Errorf("wanted hero %q but got %q", kavarze, got.Metadata.Hero)
+		}
+
+		if got.Summary.Winners[0].PlayerName != a.Username("pernadao1599") {
+			t.Errorf("wanted winner %q but got %q", a.Username("pernadao1599"), got.Summary.Winners[0].PlayerName)
+		}
+
+		if got.Metadata.Table == testHand.Metadata.Table || got.Metadata.ID == testHand.Metadata.ID {
+			t.Errorf("wanted table and hand ID replaced but got %#v", got.Metadata)
+		}
+	})
+
+	t.Run("everything else is unchanged", func(t *testing.T) {
+		restored := got
+		restored.Metadata = testHand.Metadata
+		restored.Players = []hands.Player{}
+		for i, p := range got.Players {
+			p.Username = testHand.Players[i].Username
+			restored.Players = append(restored.Players, p)
+		}
+		restored.Actions = []hands.Action{}
+		for i, act := range got.Actions {
+			act.PlayerName = testHand.Actions[i].PlayerName
+			restored.Actions = append(restored.Actions, act)
+		}
+		restored.Summary.Winners = []hands.Winner{got.Summary.Winners[0]}
+		restored.Summary.Winners[0].PlayerName = "pernadao1599"
+
+		if !reflect.DeepEqual(restored, testHand) {
+			t.Errorf("wanted %#v but got %#v", testHand, restored)
+		}
+	})
+
+	t.Run("original hand is not modified", func(t *testing.T) {
+		if testHand.Players[0].Username != "maximoIV" || testHand.Actions[0].PlayerName != "KavarzE" {
+			t.Errorf("anonymizing modified the original hand %#v", testHand)
+		}
+	})
+}
+
+func TestAnonymizerHandLeavesNoOriginalValues(t *testing.T) {
+	a, _ := New([]byte("study group"), Options{})
+
+	h := testHand
+	h.Warnings = []*hands.ParseError{{
+		File:   "HH20250119 Wei III - $0.02-$0.05 - USD No Limit Hold'em.txt",
+		HandID: "254446123323",
+		Line:   12,
+		Text:   "maximoIV: sits out",
+		Kind:   hands.KindUnrecognised,
+		Err:    errors.New("error line not recognised"),
+	}}
+
+	var out bytes.Buffer
+	if err := export.NewNDJSONEncoder(&out).Encode(a.Hand(h)); err != nil {
+		t.Fatalf("unexpected error encoding hand: %v", err)
+	}
+
+	for _, original := range []string{"maximoIV", "KavarzE", "pernadao1599", "Wei III", "254446123323"} {
+		if strings.Contains(out.String(), original) {
+			t.Errorf("wanted %q replaced but found it in %s", original, out.String())
+		}
+	}
+}
+
+func TestAnonymizerKeepHero(t *testing.T) {
+	a, _ := New([]byte("study group"), Options{KeepHero: true})
+	got := a.Hand(testHand)
+
+	if got.Metadata.Hero != "KavarzE" || got.Players[1].Username != "KavarzE" || got.Actions[0].PlayerName != "KavarzE" {
+		t.Errorf("wanted the hero kept but got %#v", got)
+	}
+
+	if got.Players[0].Username == "maximoIV" {
+		t.Errorf("wanted villains replaced but got %#v", got.Players)
+	}
+}
+
+func TestAnonymizerAliases(t *testing.T) {
+	a, _ := New([]byte("study group"), Options{})
+	b, _ := New([]byte("study group"), Options{})
+	other, _ := New([]byte("another key"), Options{})
+
+	if a.Username("maximoIV") != b.Username("maximoIV") || a.HandID("1") != b.HandID("1") || a.Table("Wei III") != b.Table("Wei III") {
+		t.Error("wanted the same aliases from the same key")
+	}
+
+	if a.Username("maximoIV") == other.Username("maximoIV") {
+		t.Error("wanted different aliases from a different key")
+	}
+
+	if a.Username("maximoIV") == a.Username("KavarzE") {
+		t.Error("wanted different aliases for different players")
+	}
+
+	if !regexp.MustCompile(`^player_[0-9a-f]{8}$`).MatchString(a.Username("maximoIV")) {
+		t.Errorf("unexpected username alias %q", a.Username("maximoIV"))
+	}
+
+	if !regexp.MustCompile(`^[1-9][0-9]{11}$`).MatchString(a.HandID("254446123323")) {
+		t.Errorf("unexpected hand ID alias %q", a.HandID("254446123323"))
+	}
+
+	if a.Table("") != "" {
+		t.Errorf("wanted an empty table to stay empty but got %q", a.Table(""))
+	}
+}
+
+func TestNewWithoutKey(t *testing.T) {
+	if _, err := New(nil, Options{}); !errors.Is(err, ErrNoKey) {
+		t.Errorf("wanted ErrNoKey but got %v", err)
+	}
+}
@@ -1,15 +1,28 @@
 package pokerhud_test
 
 import (
-	"os"
+	"pokerhud/handgen"
 	"pokerhud/hands"
 	"testing"
 )
 
-func BenchmarkHandHistoryFromFS(b *testing.B) {
-	fileSystem := os.DirFS("C:\\Users\\james\\testfolder")
+func BenchmarkExportHands(b *testing.B) {
+	const files, handsPerFile = 10, 500
+	fileSystem, _ := handgen.New(1).FS(files, handsPerFile)
+
+	if result := hands.ExportHands(fileSystem); result.HandsCount() != files*handsPerFile {
+		b.Fatalf("wanted %d hands exported but got %d", files*handsPerFile, result.HandsCount())
+	}
+
+	var size int64
+	for _, f := range fileSystem {
+		size += int64(len(f.Data))
+	}
+	b.SetBytes(size)
+	b.ReportAllocs()
 
 	for b.Loop() {
 		hands.ExportHands(fileSystem)
 	}
+	b.ReportMetric(float64(files*handsPerFile*b.N)/b.Elapsed().Seconds(), "hands/s")
 }
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"log"
+	"os"
+	"pokerhud/anonymize"
+	"pokerhud/hands"
+)
+
+// anonymizeKeyEnv is read for the anonymization key when -key is not given, keeping it out of shell history
+const anonymizeKeyEnv = "HOLDEM_ANALYTICS_ANONYMIZE_KEY"
+
+// runAnonymize parses every hand in a folder and writes them, anonymised, to a single PokerStars hand history file.
+// Hand history files are left in place.
+func runAnonymize(args []string) error {
+	flags := newFlagSet("anonymize")
+	key := flags.String("key", "", "secret the aliases are derived from, defaults to $"+anonymizeKeyEnv)
+	keepHero := flags.Bool("keep-hero", false, "leave the hero's username unchanged")
+	outPath := flags.String("out", "", "file to write the anonymised hands to")
+
+	targetDir, err := parseFolderArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if *outPath == "" {
+		return usageError{"-out is required"}
+	}
+
+	// the key is read from the environment after parsing, rather than as the flag's default, so that help and
+	// usage errors never print it
+	if *key == "" {
+		*key = os.Getenv(anonymizeKeyEnv)
+	}
+
+	anonymizer, err := anonymize.New([]byte(*key), anonymize.Options{KeepHero: *keepHero})
+	if err != nil {
+		return err
+	}
+
+	parsed, result := readHands(targetDir)
+	if result.FsErr != nil {
+		return result.FsErr
+	}
+
+	file, err := os.Create(*outPath)
+	if err != nil {
+		return err
+	}
+
+	writer := hands.NewHandWriter(file)
+	for _, h := range parsed {
+		if err := writer.Write(anonymizer.Hand(h)); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	log.Printf("Hands anonymised: %v", len(parsed))
+	log.Printf("Hand errs: %v", result.HandErrCount())
+	return nil
+}
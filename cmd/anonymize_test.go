@@ -0,0 +1,38 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAnonymizeHelpHidesKey(t *testing.T) {
+	const secret = "not-for-the-terminal"
+	t.Setenv(anonymizeKeyEnv, secret)
+
+	// the flag set writes its help to os.Stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unexpected error creating pipe: %v", err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	var out strings.Builder
+	for _, args := range [][]string{{"help", "anonymize"}, {"anonymize", "-unknown"}} {
+		run(args, w)
+	}
+	w.Close()
+	if _, err := io.Copy(&out, r); err != nil {
+		t.Fatalf("unexpected error reading output: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "-key") {
+		t.Fatalf("wanted the anonymize help but got %q", out.String())
+	}
+	if strings.Contains(out.String(), secret) {
+		t.Errorf("wanted the key to be left out of the help but got %q", out.String())
+	}
+}
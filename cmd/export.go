@@ -0,0 +1,60 @@
+package main
+
+import (
+	"cmp"
+	"os"
+	"pokerhud/export"
+	"pokerhud/hands"
+	"slices"
+)
+
+// runExport writes every hand in a folder to a file. Unlike import, the hand index is not updated and files are
+// left in place.
+func runExport(args []string) error {
+	flags := newFlagSet("export")
+	outPath := flags.String("out", "", "file to write the hands to, or directory for csv and parquet")
+	format := flags.String("format", export.FormatNDJSON, "format of the -out file: json, ndjson, csv, parquet or ohh")
+
+	targetDir, err := parseFolderArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if *outPath == "" {
+		return usageError{"-out is required"}
+	}
+
+	encoder, err := export.Create(*format, *outPath)
+	if err != nil {
+		return err
+	}
+
+	result := hands.ExportHandsWithOptions(os.DirFS(targetDir), hands.ExportOptions{OnHand: encoder.Encode})
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	logResult(result)
+	return resultErr(result)
+}
+
+// readHands parses every hand in a folder, returning them in the order they were played.
+func readHands(targetDir string) ([]hands.Hand, hands.ExportResult) {
+	var parsed []hands.Hand
+	result := hands.ExportHandsWithOptions(os.DirFS(targetDir), hands.ExportOptions{
+		OnHand: func(h hands.Hand) error {
+			parsed = append(parsed, h)
+			return nil
+		},
+	})
+
+	// hands arrive in whatever order the files were parsed in
+	sortByDate(parsed)
+	return parsed, result
+}
+
+// sortByDate orders hands by the time they were played.
+func sortByDate(hs []hands.Hand) {
+	slices.SortFunc(hs, func(a, b hands.Hand) int {
+		return cmp.Or(a.Metadata.Date.Compare(b.Metadata.Date), cmp.Compare(a.Metadata.ID, b.Metadata.ID))
+	})
+}
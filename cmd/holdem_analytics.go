@@ -1,45 +1,163 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"os"
-	"path/filepath"
-	"pokerhud/fileutil"
-	"pokerhud/hands"
 )
 
-func main() {
-	args := os.Args
-	argsLen := len(args)
-	if argsLen > 2 {
-		log.Fatal("too many arguments provided.\n example usage: ./holdem-analytics <hand history folder path>")
-	} else if argsLen < 2 {
-		log.Fatal("not enough arguments provided.\n example usage: ./holdem-analytics <hand history folder path>")
+// Exit codes
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// command is a subcommand of the CLI.
+type command struct {
+	name    string
+	summary string
+	usage   string // arguments following the command name
+	run     func(args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"import", "parse new hands, update the hand index and move, copy or leave processed files", "[-incremental] [-files leave|copy|move] [-dest <dir>] [-dry-run] [-parse-mode default|strict|lenient] [-max-fail-rate <fraction>] [-quarantine <dir>] [-report <path|-> [-report-format json|table]] [-out <path> -format <format>] <hand history folder>", runImport},
+		{"retry", "parse the hands quarantined by previous imports again, importing those that now parse", "[-out <path> -format <format>] [<quarantine file>...]", runRetry},
+		{"watch", "import hands as they are played and print the updated statistics of their players", "[-interval <duration>] [-out <path> -format <format>] <hand history folder>", runWatch},
+		{"hud", "show the statistics of the players at each active table, updated as hands are played", "[-hero <name>] [-active <duration>] [-interval <duration>] <hand history folder>", runHUD},
+		{"serve", "serve hands and statistics over a local HTTP JSON API", "[-addr <host:port>] [-watch [-interval <duration>]] <hand history folder>", runServe},
+		{"export", "write every hand in a folder to a file without importing it", "-out <path> [-format <format>] <hand history folder>", runExport},
+		{"stats", "print per-player statistics for the hands in a folder", "[-player <name>] [-min-hands <n>] <hand history folder>", runStats},
+		{"validate", "report files and hands that fail to parse, exiting non-zero if any do", "<hand history folder>", runValidate},
+		{"replay", "print hands as PokerStars hand history text", "[-id <hand id>] <hand history folder>", runReplay},
+		{"anonymize", "write anonymised copies of the hands in a folder", "-key <secret> [-keep-hero] -out <file> <hand history folder>", runAnonymize},
+		{"help", "show help for a command", "[command]", runHelp},
 	}
+}
+
+// usageError is returned by commands whose arguments are invalid, the CLI prints the message and the command's
+// usage and exits with exitUsage. An empty message means the problem has already been reported.
+type usageError struct {
+	msg string
+}
 
-	targetDir := args[1]
-	proccessedDir := filepath.Join(targetDir, "Processed By Holdem Analytics")
+func (e usageError) Error() string {
+	return e.msg
+}
 
-	fileSystem := os.DirFS(targetDir)
+func main() {
+	log.SetFlags(0)
+	os.Exit(run(os.Args[1:], os.Stderr))
+}
 
-	result := hands.ExportHands(fileSystem)
+// run runs the command named by the first argument and returns the exit code.
+func run(args []string, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return exitUsage
+	}
 
-	for _, f := range result.SuccessFiles() {
-		oldPath := filepath.Join(targetDir, f)
-		newPath := filepath.Join(proccessedDir, f)
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		printUsage(stderr)
+		return exitUsage
+	}
 
-		err := fileutil.MoveProcessedFiles(oldPath, newPath)
+	err := cmd.run(args[1:])
+
+	var usageErr usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usageErr):
+		if usageErr.msg != "" {
+			fmt.Fprintf(stderr, "%s\n\n", usageErr.msg)
+			printCommandUsage(stderr, cmd)
+		}
+		return exitUsage
+	default:
+		fmt.Fprintf(stderr, "%s: %v\n", cmd.name, err)
+		return exitFailure
+	}
+}
 
-		if err != nil {
-			log.Printf("error moving file %s", err.Error())
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
 		}
 	}
+	return command{}, false
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: holdem-analytics <command> [arguments]")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nrun 'holdem-analytics help <command>' for the arguments of a command")
+}
+
+func printCommandUsage(w io.Writer, cmd command) {
+	fmt.Fprintf(w, "usage: holdem-analytics %s %s\n", cmd.name, cmd.usage)
+}
+
+func runHelp(args []string) error {
+	if len(args) == 0 {
+		printUsage(os.Stdout)
+		return nil
+	}
+
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		return usageError{fmt.Sprintf("unknown command %q", args[0])}
+	}
 
-	log.Printf(
-		"Successful Files: %#v\nFailedFiles: %#v\nFsError: %#v", result.SuccessCount(), result.FileErrorCount(), result.FsErr,
-	)
+	if cmd.name == "help" {
+		printCommandUsage(os.Stdout, cmd)
+		return nil
+	}
+	return cmd.run([]string{"-h"})
+}
 
-	log.Printf("Hands parsed: %v", result.HandsCount())
-	log.Printf("Hand errs: %v", result.HandErrCount())
+// newFlagSet returns a flag set for cmd whose help output includes the command's usage. Parse errors are returned
+// rather than exiting so that the exit code is decided in one place.
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		cmd, _ := findCommand(name)
+		printCommandUsage(flags.Output(), cmd)
+		flags.PrintDefaults()
+	}
+	return flags
+}
 
+// parseFolderArgs parses args with flags and returns the single hand history folder argument.
+func parseFolderArgs(flags *flag.FlagSet, args []string) (string, error) {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return "", err
+		}
+		return "", usageError{} // the flag set has already reported the error
+	}
+
+	switch flags.NArg() {
+	case 0:
+		return "", usageError{"no hand history folder provided"}
+	case 1:
+		return flags.Arg(0), nil
+	default:
+		return "", usageError{"too many arguments provided"}
+	}
 }
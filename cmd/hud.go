@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"pokerhud/hands"
+	"pokerhud/hud"
+)
+
+// clearScreen moves the cursor home and clears the terminal, so that each frame of the HUD replaces the last
+const clearScreen = "\x1b[H\x1b[2J"
+
+// runHUD shows the statistics of the players at each active table in the terminal until interrupted, redrawing as
+// hands are completed. The hand index and import ledger are left untouched.
+func runHUD(args []string) error {
+	flags := newFlagSet("hud")
+	interval := flags.Duration("interval", hands.DefaultWatchInterval, "time between polls of the folder")
+	hero := flags.String("hero", "", "player whose session is shown (default the player hands were dealt to)")
+	active := flags.Duration("active", hud.DefaultActiveWindow, "hide tables without a hand this long before the latest hand")
+
+	targetDir, err := parseFolderArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	display := hud.New()
+	display.Hero = *hero
+	display.ActiveWindow = *active
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// parse errors would be drawn over by the next frame, and repeat on every poll, validate reports them instead
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	frame := bytes.NewBufferString(clearScreen)
+	redraw := func() {
+		frame.Truncate(len(clearScreen))
+		display.Render(frame) // writing to a bytes.Buffer can't fail
+		os.Stdout.Write(frame.Bytes())
+	}
+	redraw()
+
+	hands.Watch(ctx, os.DirFS(targetDir), hands.WatchOptions{
+		ExportOptions: hands.ExportOptions{
+			OnHand: func(h hands.Hand) error {
+				display.Add(h)
+				return nil
+			},
+		},
+		Interval: *interval,
+		OnPoll: func(result hands.ExportResult) {
+			if result.HandsCount() > 0 {
+				redraw()
+			}
+		},
+	})
+	return nil
+}
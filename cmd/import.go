@@ -0,0 +1,315 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"pokerhud/export"
+	"pokerhud/fileutil"
+	"pokerhud/hands"
+	"pokerhud/report"
+	"time"
+)
+
+// processedDirName is the folder within a hand history folder that imported files are moved to by default
+const processedDirName = "Processed By Holdem Analytics"
+
+// What happens to hand history files once they have been imported
+const (
+	filesLeave = "leave"
+	filesCopy  = "copy"
+	filesMove  = "move"
+)
+
+// Parse modes accepted by -parse-mode
+const (
+	parseDefault = "default"
+	parseStrict  = "strict"
+	parseLenient = "lenient"
+)
+
+var parseModes = map[string]hands.ParseMode{
+	parseDefault: hands.ParseDefault,
+	parseStrict:  hands.ParseStrict,
+	parseLenient: hands.ParseLenient,
+}
+
+// runImport parses the hands in a folder that have not been imported before, recording them in the hand index.
+// Imported files are moved to the processed folder by default, see -files for the alternatives.
+func runImport(args []string) error {
+	flags := newFlagSet("import")
+	incremental := flags.Bool("incremental", false, "only parse hands appended since the last run, files are left in place by default")
+	outPath := flags.String("out", "", "write every newly imported hand to this file, or directory for csv and parquet")
+	format := flags.String("format", export.FormatNDJSON, "format of the -out file: json, ndjson, csv, parquet or ohh")
+	filesMode := flags.String("files", "", "what to do with imported files: leave, copy or move (default move, or leave with -incremental)")
+	destDir := flags.String("dest", "", "folder imported files are copied or moved to (default \"<hand history folder>/"+processedDirName+"\")")
+	dryRun := flags.Bool("dry-run", false, "report what would be imported without saving state, exporting or touching any file")
+	reportPath := flags.String("report", "", "write a per-file import report to this file, or - for stdout")
+	reportFormat := flags.String("report-format", report.FormatJSON, "format of the -report: json or table")
+	parseMode := flags.String("parse-mode", parseDefault, "how hands that fail to parse are treated: default skips them, strict stops at the first, lenient keeps hands with unparsable lines")
+	maxFailRate := flags.Float64("max-fail-rate", hands.DefaultMaxFailRate, "fraction of a file's hands that may fail to parse before the file fails")
+	quarantinePath := flags.String("quarantine", "", "folder the hands that fail to parse are written to, see the retry command (default the state folder)")
+
+	targetDir, err := parseFolderArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	mode := *filesMode
+	switch {
+	case mode == "" && *incremental:
+		mode = filesLeave
+	case mode == "":
+		mode = filesMove
+	case mode != filesLeave && mode != filesCopy && mode != filesMove:
+		return usageError{fmt.Sprintf("unknown -files mode %q", mode)}
+	case mode == filesMove && *incremental:
+		return usageError{"-files move can't be used with -incremental, files still being written would be moved"}
+	}
+
+	if *destDir == "" {
+		*destDir = filepath.Join(targetDir, processedDirName)
+	}
+
+	if *dryRun && *outPath != "" {
+		return usageError{"-out can't be used with -dry-run"}
+	}
+
+	parse, ok := parseModes[*parseMode]
+	if !ok {
+		return usageError{fmt.Sprintf("unknown -parse-mode %q", *parseMode)}
+	}
+
+	if *maxFailRate <= 0 || *maxFailRate > 1 {
+		return usageError{"-max-fail-rate must be greater than 0 and at most 1, use -parse-mode strict to allow no failures"}
+	}
+
+	if *reportFormat != report.FormatJSON && *reportFormat != report.FormatTable {
+		return usageError{fmt.Sprintf("unknown -report-format %q", *reportFormat)}
+	}
+
+	indexPath, err := handIndexPath()
+	if err != nil {
+		return err
+	}
+
+	index, err := loadHandIndex(indexPath)
+	if err != nil {
+		return err
+	}
+
+	if *quarantinePath == "" {
+		*quarantinePath, err = quarantineDir()
+		if err != nil {
+			return err
+		}
+	}
+
+	opts := hands.ExportOptions{
+		Index:       index,
+		Quarantine:  previouslyQuarantined(*quarantinePath),
+		Mode:        parse,
+		MaxFailRate: *maxFailRate,
+	}
+
+	var ledgerPath string
+	if *incremental {
+		ledgerPath, err = ledgerPathFor(targetDir)
+		if err != nil {
+			return err
+		}
+
+		opts.Ledger, err = loadLedger(ledgerPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	var encoder export.Encoder
+	if *outPath != "" {
+		encoder, err = export.Create(*format, *outPath)
+		if err != nil {
+			return err
+		}
+		opts.OnHand = encoder.Encode
+	}
+
+	result := hands.ExportHandsWithOptions(os.DirFS(targetDir), opts)
+
+	if *dryRun {
+		processImportedFiles(mode, targetDir, *destDir, result.SuccessFiles(), true)
+		logResult(result)
+		writeReport(*reportPath, *reportFormat, result)
+		return resultErr(result)
+	}
+
+	// an aborted import records nothing, so that the next import starts over from the same state
+	if result.AbortErr != nil {
+		if encoder != nil {
+			encoder.Close()
+			log.Printf("the import was aborted, %s is incomplete", *outPath)
+		}
+		logResult(result)
+		writeReport(*reportPath, *reportFormat, result)
+		return resultErr(result)
+	}
+
+	if encoder != nil {
+		exportErr := result.OnHandErr
+		if err := encoder.Close(); exportErr == nil {
+			exportErr = err
+		}
+
+		// imported hands are only recorded once they are safely exported, so that the next import exports them again
+		if exportErr != nil {
+			logResult(result)
+			writeReport(*reportPath, *reportFormat, result)
+			return fmt.Errorf("exporting hands: %w", exportErr)
+		}
+	}
+
+	if err := saveState(indexPath, index); err != nil {
+		log.Printf("error saving hand index %s", err.Error())
+	}
+
+	if *incremental {
+		if err := saveState(ledgerPath, opts.Ledger); err != nil {
+			log.Printf("error saving import ledger %s", err.Error())
+		}
+	}
+
+	saveQuarantine(*quarantinePath, opts.Quarantine)
+
+	processImportedFiles(mode, targetDir, *destDir, result.SuccessFiles(), false)
+
+	logResult(result)
+	writeReport(*reportPath, *reportFormat, result)
+	return resultErr(result)
+}
+
+// processImportedFiles leaves, copies or moves each imported file to destDir according to mode. When dryRun is set
+// it only logs what would be done.
+func processImportedFiles(mode, targetDir, destDir string, files []string, dryRun bool) {
+	if mode == filesLeave {
+		if dryRun {
+			log.Printf("would leave %d files in place", len(files))
+		}
+		return
+	}
+
+	for _, f := range files {
+		oldPath := filepath.Join(targetDir, f)
+		newPath := filepath.Join(destDir, f)
+
+		if dryRun {
+			log.Printf("would %s %s to %s", mode, oldPath, newPath)
+			continue
+		}
+
+		var err error
+		if mode == filesCopy {
+			err = fileutil.CopyProcessedFiles(oldPath, newPath)
+		} else {
+			err = fileutil.MoveProcessedFiles(oldPath, newPath)
+		}
+
+		if err != nil {
+			log.Printf("error processing file %s", err.Error())
+		}
+	}
+}
+
+// previouslyQuarantined returns an empty Quarantine that excludes the hands already quarantined to dir by earlier
+// imports, so that a file imported again does not quarantine its failing hands twice.
+func previouslyQuarantined(dir string) *hands.Quarantine {
+	quarantine := hands.NewQuarantine()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		log.Printf("error listing quarantined hands %s", err.Error())
+		return quarantine
+	}
+
+	for _, path := range paths {
+		previous, err := loadQuarantine(path)
+		if err != nil {
+			log.Printf("error reading quarantine %s: %s", path, err.Error())
+			continue
+		}
+		quarantine.Exclude(previous)
+	}
+	return quarantine
+}
+
+// saveQuarantine writes the hands that failed to parse during this import to a new file in dir, named after the
+// time of the import. Nothing is written if every hand parsed.
+func saveQuarantine(dir string, quarantine *hands.Quarantine) {
+	if quarantine.Len() == 0 {
+		return
+	}
+
+	path := filepath.Join(dir, time.Now().Format("20060102-150405.000")+".json")
+	if err := saveState(path, quarantine); err != nil {
+		log.Printf("error saving quarantined hands %s", err.Error())
+		return
+	}
+	log.Printf("Quarantined %d hands to %s", quarantine.Len(), path)
+}
+
+func logResult(result hands.ExportResult) {
+	log.Printf("Successful files: %v", result.SuccessCount())
+	log.Printf("Failed files: %v", result.FileErrorCount())
+	log.Printf("Hands parsed: %v", result.HandsCount())
+	log.Printf("Hand errs: %v", result.HandErrCount())
+	log.Printf("Duplicate hands: %v", result.DuplicateCount())
+	if n := result.RecoveredCount(); n > 0 {
+		log.Printf("Hands kept with warnings: %v", n)
+	}
+}
+
+// writeReport writes the report of result to path, or stdout if path is "-". Nothing is written if path is empty.
+func writeReport(path, format string, result hands.ExportResult) {
+	if path == "" {
+		return
+	}
+
+	r := report.New(result)
+	if path == "-" {
+		if err := r.Write(os.Stdout, format); err != nil {
+			log.Printf("error writing import report %s", err.Error())
+		}
+		return
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		log.Printf("error writing import report %s", err.Error())
+		return
+	}
+
+	err = r.Write(file, format)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Printf("error writing import report %s", err.Error())
+	}
+}
+
+// resultErr returns an error describing why an import was not entirely successful, or nil if it was.
+func resultErr(result hands.ExportResult) error {
+	if result.FsErr != nil {
+		return result.FsErr
+	}
+	if result.OnHandErr != nil {
+		return result.OnHandErr
+	}
+	if result.AbortErr != nil {
+		return fmt.Errorf("import aborted: %w", result.AbortErr)
+	}
+	if n := result.FileErrorCount(); n > 0 {
+		return fmt.Errorf("%d of %d files failed to import", n, len(result.FileResults))
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"pokerhud/hands"
+)
+
+// runReplay prints the hands in a folder, or a single hand, as PokerStars hand history text.
+func runReplay(args []string) error {
+	flags := newFlagSet("replay")
+	id := flags.String("id", "", "only print the hand with this ID")
+
+	targetDir, err := parseFolderArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	parsed, result := readHands(targetDir)
+	if result.FsErr != nil {
+		return result.FsErr
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	writer := hands.NewHandWriter(out)
+	found := false
+	for _, h := range parsed {
+		if *id != "" && h.Metadata.ID != *id {
+			continue
+		}
+		found = true
+		if err := writer.Write(h); err != nil {
+			return err
+		}
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+
+	if *id != "" && !found {
+		return fmt.Errorf("hand %s was not found", *id)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"pokerhud/export"
+	"pokerhud/hands"
+)
+
+// runRetry parses the hands quarantined by previous imports again, typically after a parser upgrade. Hands that now
+// parse are recorded in the hand index, and each quarantine file is rewritten with the hands that still fail or
+// removed once none do.
+func runRetry(args []string) error {
+	flags := newFlagSet("retry")
+	outPath := flags.String("out", "", "write every recovered hand to this file, or directory for csv and parquet")
+	format := flags.String("format", export.FormatNDJSON, "format of the -out file: json, ndjson, csv, parquet or ohh")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{} // the flag set has already reported the error
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		dir, err := quarantineDir()
+		if err != nil {
+			return err
+		}
+
+		paths, err = filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(paths) == 0 {
+		log.Printf("No quarantined hands")
+		return nil
+	}
+
+	indexPath, err := handIndexPath()
+	if err != nil {
+		return err
+	}
+
+	index, err := loadHandIndex(indexPath)
+	if err != nil {
+		return err
+	}
+
+	var encoder export.Encoder
+	if *outPath != "" {
+		encoder, err = export.Create(*format, *outPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	remaining := map[string]*hands.Quarantine{}
+	var recovered, duplicates, failed int
+	var exportErr error
+
+	for _, path := range paths {
+		quarantine, err := loadQuarantine(path)
+		if err != nil {
+			return fmt.Errorf("reading quarantine %s: %w", path, err)
+		}
+
+		parsed, stillFailing := quarantine.Retry()
+		for _, h := range parsed {
+			if !index.Add(h.Metadata.Key()) {
+				duplicates++
+				continue
+			}
+			recovered++
+
+			if encoder != nil && exportErr == nil {
+				exportErr = encoder.Encode(h)
+			}
+		}
+
+		for _, qh := range stillFailing.Hands() {
+			log.Printf("error parsing hand %s", qh.Error)
+		}
+		failed += stillFailing.Len()
+		remaining[path] = stillFailing
+	}
+
+	if encoder != nil {
+		if err := encoder.Close(); exportErr == nil {
+			exportErr = err
+		}
+	}
+
+	// recovered hands are only taken out of quarantine once they are safely recorded
+	if exportErr != nil {
+		return fmt.Errorf("exporting hands: %w", exportErr)
+	}
+	if err := saveState(indexPath, index); err != nil {
+		return fmt.Errorf("saving hand index: %w", err)
+	}
+
+	for path, quarantine := range remaining {
+		if quarantine.Len() == 0 {
+			err = os.Remove(path)
+		} else {
+			err = saveState(path, quarantine)
+		}
+
+		if err != nil {
+			log.Printf("error updating quarantine %s", err.Error())
+		}
+	}
+
+	log.Printf("Hands recovered: %v", recovered)
+	log.Printf("Duplicate hands: %v", duplicates)
+	log.Printf("Hands still failing: %v", failed)
+
+	if failed > 0 {
+		return fmt.Errorf("%d quarantined hands still fail to parse", failed)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"pokerhud/hands"
+	"pokerhud/server"
+)
+
+// runServe serves the hands in a folder, and their players' statistics, over a local HTTP JSON API. See package
+// server for the endpoints. With -watch, hands are added as they are completed and streamed to /api/events.
+func runServe(args []string) error {
+	flags := newFlagSet("serve")
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	watch := flags.Bool("watch", false, "keep polling the folder for new hands")
+	interval := flags.Duration("interval", hands.DefaultWatchInterval, "time between polls of the folder with -watch")
+
+	targetDir, err := parseFolderArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	// the ledger lets the watcher carry on from where the initial load stopped, including any unfinished hand
+	opts := hands.ExportOptions{Index: hands.NewHandIndex(), Ledger: hands.NewLedger()}
+	var parsed []hands.Hand
+	opts.OnHand = func(h hands.Hand) error {
+		parsed = append(parsed, h)
+		return nil
+	}
+
+	fileSystem := os.DirFS(targetDir)
+	result := hands.ExportHandsWithOptions(fileSystem, opts)
+	if result.FsErr != nil {
+		return result.FsErr
+	}
+
+	// added in the order they were played, so that events clients reconnecting after a restart replay later hands
+	sortByDate(parsed)
+	srv := server.New()
+	for _, h := range parsed {
+		srv.Add(h)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *watch {
+		opts.OnHand = func(h hands.Hand) error {
+			srv.Add(h)
+			return nil
+		}
+		go hands.Watch(ctx, fileSystem, hands.WatchOptions{ExportOptions: opts, Interval: *interval})
+	}
+
+	httpServer := &http.Server{Addr: *addr, Handler: srv}
+	go func() {
+		<-ctx.Done()
+		httpServer.Close()
+	}()
+
+	log.Printf("serving %d hands from %s on http://%s/api/", len(parsed), targetDir, *addr)
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
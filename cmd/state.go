@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"pokerhud/hands"
+)
+
+// stateDir returns the directory holding state shared between runs, such as the hand index and import ledgers.
+func stateDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "holdem-analytics"), nil
+}
+
+// handIndexPath returns the location of the index of previously imported hands, shared by every import run.
+func handIndexPath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "hand-index.txt"), nil
+}
+
+// ledgerPathFor returns the location of the import ledger for the hand history folder targetDir. Each folder has
+// its own ledger, named after a hash of its absolute path.
+func ledgerPathFor(targetDir string) (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+
+	absDir, err := filepath.Abs(targetDir)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(absDir))
+	return filepath.Join(dir, "ledgers", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// quarantineDir returns the directory each import writes the hands that failed to parse to, one file per import.
+func quarantineDir() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "quarantine"), nil
+}
+
+func loadHandIndex(path string) (*hands.HandIndex, error) {
+	index := hands.NewHandIndex()
+	err := loadState(path, func(r io.Reader) (err error) {
+		index, err = hands.ReadHandIndex(r)
+		return err
+	})
+	return index, err
+}
+
+func loadLedger(path string) (*hands.Ledger, error) {
+	ledger := hands.NewLedger()
+	err := loadState(path, func(r io.Reader) (err error) {
+		ledger, err = hands.ReadLedger(r)
+		return err
+	})
+	return ledger, err
+}
+
+func loadQuarantine(path string) (*hands.Quarantine, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return hands.ReadQuarantine(file)
+}
+
+// loadState opens the state file at path and passes it to read. A missing file is not an error, read is simply
+// not called.
+func loadState(path string, read func(io.Reader) error) error {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return read(file)
+}
+
+// saveState writes state to path, creating the parent directory if required. The file is written under a temporary
+// name first so that an interrupted run never leaves a truncated state file behind.
+func saveState(path string, state io.WriterTo) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := state.WriteTo(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"pokerhud/stats"
+	"text/tabwriter"
+)
+
+// runStats prints the statistics of every player in the hands in a folder.
+func runStats(args []string) error {
+	flags := newFlagSet("stats")
+	player := flags.String("player", "", "only print the statistics of this player")
+	minHands := flags.Int("min-hands", 1, "only print players seen in at least this many hands")
+
+	targetDir, err := parseFolderArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	parsed, result := readHands(targetDir)
+	if result.FsErr != nil {
+		return result.FsErr
+	}
+
+	s := stats.New()
+	for _, h := range parsed {
+		s.Add(h)
+	}
+
+	var players []stats.PlayerStats
+	if *player != "" {
+		ps, ok := s.Player(*player)
+		if !ok {
+			return fmt.Errorf("player %q was not found in any hand", *player)
+		}
+		players = append(players, ps)
+	} else {
+		for _, ps := range s.Players() {
+			if ps.Hands >= *minHands {
+				players = append(players, ps)
+			}
+		}
+	}
+
+	return writeStatsTable(os.Stdout, players)
+}
+
+func writeStatsTable(w io.Writer, players []stats.PlayerStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Player\tHands\tVPIP\tPFR\t3Bet\tAF\tNet\t")
+	for _, ps := range players {
+		fmt.Fprintf(tw, "%s\t%d\t%.1f\t%.1f\t%.1f\t%.2f\t%.2f\t\n",
+			ps.Player, ps.Hands, ps.VPIP(), ps.PFR(), ps.ThreeBet(), ps.AF(), ps.Net)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"pokerhud/hands"
+	"pokerhud/report"
+	"text/tabwriter"
+)
+
+// runValidate parses the hands in a folder without importing them and reports each file's result, followed by the
+// lines the parser did not recognise. It fails if any file or hand could not be parsed.
+func runValidate(args []string) error {
+	flags := newFlagSet("validate")
+
+	targetDir, err := parseFolderArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	result := hands.ExportHands(os.DirFS(targetDir))
+	if result.FsErr != nil {
+		return result.FsErr
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "File\tHands\tHand errs\tError")
+	for _, fr := range result.FileResults {
+		errText := ""
+		if fr.Err != nil {
+			errText = fr.Err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", fr.Path, fr.HandsParsed, fr.HandErrs, errText)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if lines := report.New(result).UnrecognisedLines; len(lines) > 0 {
+		if err := report.WriteUnrecognisedLines(os.Stdout, lines); err != nil {
+			return err
+		}
+	}
+
+	if result.FileErrorCount() > 0 || result.HandErrCount() > 0 {
+		return fmt.Errorf("%d files and %d hands failed to parse", result.FileErrorCount(), result.HandErrCount())
+	}
+	return nil
+}
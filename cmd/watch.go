@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"pokerhud/export"
+	"pokerhud/hands"
+	"pokerhud/stats"
+)
+
+// runWatch polls a folder until interrupted, importing hands as they are completed and printing the updated
+// statistics of the players in them. Files are left in place, progress is kept in the folder's import ledger.
+func runWatch(args []string) error {
+	flags := newFlagSet("watch")
+	interval := flags.Duration("interval", hands.DefaultWatchInterval, "time between polls of the folder")
+	outPath := flags.String("out", "", "write every newly imported hand to this file, or directory for csv and parquet")
+	format := flags.String("format", export.FormatNDJSON, "format of the -out file: json, ndjson, csv, parquet or ohh")
+
+	targetDir, err := parseFolderArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	indexPath, err := handIndexPath()
+	if err != nil {
+		return err
+	}
+
+	index, err := loadHandIndex(indexPath)
+	if err != nil {
+		return err
+	}
+
+	ledgerPath, err := ledgerPathFor(targetDir)
+	if err != nil {
+		return err
+	}
+
+	ledger, err := loadLedger(ledgerPath)
+	if err != nil {
+		return err
+	}
+
+	// statistics cover every hand in the folder, not only those imported while watching
+	s := stats.New()
+	seen := map[hands.HandKey]bool{}
+	parsed, result := readHands(targetDir)
+	if result.FsErr != nil {
+		return result.FsErr
+	}
+	for _, h := range parsed {
+		s.Add(h)
+		seen[h.Metadata.Key()] = true
+	}
+
+	var encoder export.Encoder
+	if *outPath != "" {
+		encoder, err = export.Create(*format, *outPath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := encoder.Close(); err != nil {
+				log.Printf("error exporting hands %s", err.Error())
+			}
+		}()
+	}
+
+	updated := map[string]bool{}
+	onHand := func(h hands.Hand) error {
+		if !seen[h.Metadata.Key()] {
+			s.Add(h)
+			seen[h.Metadata.Key()] = true
+		}
+		for _, p := range h.Players {
+			updated[p.Username] = true
+		}
+
+		if encoder != nil {
+			return encoder.Encode(h)
+		}
+		return nil
+	}
+
+	onPoll := func(result hands.ExportResult) {
+		if result.FsErr != nil {
+			log.Printf("error reading %s %s", targetDir, result.FsErr.Error())
+			return
+		}
+		if result.OnHandErr != nil {
+			log.Printf("error exporting hands %s", result.OnHandErr.Error())
+		}
+
+		// the index is saved first, so that the ledger never records hands the index is missing. The ledger moves
+		// on for files read without new hands too, e.g. those holding only duplicates or a hand still being written.
+		if result.HandsCount() > 0 {
+			if err := saveState(indexPath, index); err != nil {
+				log.Printf("error saving hand index %s", err.Error())
+			}
+		}
+		if result.LedgerChanged {
+			if err := saveState(ledgerPath, ledger); err != nil {
+				log.Printf("error saving import ledger %s", err.Error())
+			}
+		}
+		if result.HandsCount() == 0 {
+			return
+		}
+
+		log.Printf("imported %d new hands", result.HandsCount())
+		var players []stats.PlayerStats
+		for _, ps := range s.Players() {
+			if updated[ps.Player] {
+				players = append(players, ps)
+			}
+		}
+		clear(updated)
+
+		if err := writeStatsTable(os.Stdout, players); err != nil {
+			log.Printf("error printing statistics %s", err.Error())
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	log.Printf("watching %s, press Ctrl+C to stop", targetDir)
+	// Watch only returns once interrupted, which is how watching is meant to end
+	hands.Watch(ctx, os.DirFS(targetDir), hands.WatchOptions{
+		ExportOptions: hands.ExportOptions{Index: index, Ledger: ledger, OnHand: onHand},
+		Interval:      *interval,
+		OnPoll:        onPoll,
+	})
+	return nil
+}
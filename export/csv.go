@@ -0,0 +1,182 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"pokerhud/hands"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSV table file names written by CreateCSVEncoder
+const (
+	HandsCSV   = "hands.csv"
+	PlayersCSV = "players.csv"
+	ActionsCSV = "actions.csv"
+	WinnersCSV = "winners.csv"
+)
+
+var (
+	handsHeader   = []string{"hand_id", "site", "date", "button_seat", "pot", "rake", "board_1", "board_2"}
+	playersHeader = []string{"hand_id", "seat", "username", "chip_count", "card_1", "card_2"}
+	actionsHeader = []string{"hand_id", "order", "street", "player", "action_type", "amount"}
+	winnersHeader = []string{"hand_id", "player", "amount", "board"}
+)
+
+// CSVEncoder writes hands as four relational CSV tables: hands, players, actions and winners.
+type CSVEncoder struct {
+	hands   *csv.Writer
+	players *csv.Writer
+	actions *csv.Writer
+	winners *csv.Writer
+	started bool
+}
+
+// NewCSVEncoder returns a CSVEncoder writing each table to its own writer.
+func NewCSVEncoder(handsW, playersW, actionsW, winnersW io.Writer) *CSVEncoder {
+	return &CSVEncoder{
+		hands:   csv.NewWriter(handsW),
+		players: csv.NewWriter(playersW),
+		actions: csv.NewWriter(actionsW),
+		winners: csv.NewWriter(winnersW),
+	}
+}
+
+// CreateCSVEncoder creates the directory dir if required and returns a CSVEncoder writing the tables to files
+// within it. Closing the encoder closes the files.
+func CreateCSVEncoder(dir string) (Encoder, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+
+	names := []string{HandsCSV, PlayersCSV, ActionsCSV, WinnersCSV}
+	files := make([]io.Closer, 0, len(names))
+	writers := make([]io.Writer, 0, len(names))
+
+	for _, name := range names {
+		file, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			for _, f := range files {
+				f.Close()
+			}
+			return nil, err
+		}
+		files = append(files, file)
+		writers = append(writers, file)
+	}
+
+	enc := NewCSVEncoder(writers[0], writers[1], writers[2], writers[3])
+	return fileEncoder{enc, files}, nil
+}
+
+// Encode appends the rows describing h to each table. The header rows are written before the first hand.
+func (e *CSVEncoder) Encode(h hands.Hand) error {
+	if !e.started {
+		e.started = true
+		if err := e.writeHeaders(); err != nil {
+			return err
+		}
+	}
+
+	id := h.Metadata.ID
+	boards := h.Summary.CommunityCards
+
+	err := e.hands.Write([]string{
+		id,
+		h.Metadata.Site,
+		h.Metadata.Date.UTC().Format(time.RFC3339),
+		strconv.Itoa(h.Metadata.ButtonSeat),
+		formatAmount(h.Summary.Pot),
+		formatAmount(h.Summary.Rake),
+		joinCards(boardCards(boards[0])),
+		joinCards(boardCards(boards[1])),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range h.Players {
+		err := e.players.Write([]string{
+			id,
+			strconv.Itoa(p.Seat),
+			p.Username,
+			formatAmount(p.ChipCount),
+			string(p.Cards[0]),
+			string(p.Cards[1]),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, a := range h.Actions {
+		err := e.actions.Write([]string{
+			id,
+			strconv.Itoa(a.Order),
+			string(a.Street),
+			a.PlayerName,
+			a.ActionType.String(),
+			formatAmount(a.Amount),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, w := range h.Summary.Winners {
+		err := e.winners.Write([]string{id, w.PlayerName, formatAmount(w.Amount), strconv.Itoa(w.Board)})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close flushes every table, writing the header rows if no hands were encoded. It does not close the underlying
+// writers.
+func (e *CSVEncoder) Close() error {
+	if !e.started {
+		e.started = true
+		if err := e.writeHeaders(); err != nil {
+			return err
+		}
+	}
+
+	for _, w := range e.writers() {
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *CSVEncoder) writers() []*csv.Writer {
+	return []*csv.Writer{e.hands, e.players, e.actions, e.winners}
+}
+
+func (e *CSVEncoder) writeHeaders() error {
+	headers := [][]string{handsHeader, playersHeader, actionsHeader, winnersHeader}
+	for i, w := range e.writers() {
+		if err := w.Write(headers[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
+
+func joinCards(cards []hands.Card) string {
+	s := make([]string, len(cards))
+	for i, c := range cards {
+		s[i] = string(c)
+	}
+	return strings.Join(s, " ")
+}
@@ -0,0 +1,88 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCSVEncoder(t *testing.T) {
+
+	t.Run("hand is split into relational tables", func(t *testing.T) {
+		var handsBuf, playersBuf, actionsBuf, winnersBuf bytes.Buffer
+		enc := NewCSVEncoder(&handsBuf, &playersBuf, &actionsBuf, &winnersBuf)
+
+		if err := enc.Encode(testHand); err != nil {
+			t.Fatalf("unexpected error encoding hand: %v", err)
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("unexpected error closing encoder: %v", err)
+		}
+
+		cases := []struct {
+			name string
+			buf  *bytes.Buffer
+			want [][]string
+		}{
+			{"hands", &handsBuf, [][]string{
+				handsHeader,
+				{"254446123323", "PokerStars", "2025-01-19T12:38:55Z", "1", "0.22", "0.01", "2h Ts Jc", ""},
+			}},
+			{"players", &playersBuf, [][]string{
+				playersHeader,
+				{"254446123323", "1", "maximoIV", "5.2", "", ""},
+				{"254446123323", "6", "pernadao1599", "3.43", "Jh", "Qc"},
+			}},
+			{"actions", &actionsBuf, [][]string{
+				actionsHeader,
+				{"254446123323", "1", "preflop", "pernadao1599", "post", "0.02"},
+				{"254446123323", "2", "preflop", "maximoIV", "post", "0.05"},
+				{"254446123323", "3", "flop", "pernadao1599", "bet", "0.1"},
+				{"254446123323", "4", "flop", "maximoIV", "fold", "0"},
+			}},
+			{"winners", &winnersBuf, [][]string{
+				winnersHeader,
+				{"254446123323", "pernadao1599", "0.21", "1"},
+			}},
+		}
+
+		for _, tt := range cases {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := csv.NewReader(tt.buf).ReadAll()
+				if err != nil {
+					t.Fatalf("unexpected error reading csv: %v", err)
+				}
+
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("wanted %v but got %v", tt.want, got)
+				}
+			})
+		}
+	})
+
+	t.Run("tables are created in the output directory", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "csv")
+
+		enc, err := Create(FormatCSV, dir)
+		if err != nil {
+			t.Fatalf("unexpected error creating encoder: %v", err)
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("unexpected error closing encoder: %v", err)
+		}
+
+		for _, name := range []string{HandsCSV, PlayersCSV, ActionsCSV, WinnersCSV} {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Errorf("wanted %s to be created but got %v", name, err)
+			}
+
+			if len(data) == 0 {
+				t.Errorf("wanted a header row in %s but it was empty", name)
+			}
+		}
+	})
+}
@@ -0,0 +1,126 @@
+// Package export provides encoders that write parsed hands in formats suitable for downstream analytics tools.
+//
+// # JSON schema
+//
+// The JSON and NDJSON encoders share a single schema, versioned by SchemaVersion. A JSON document is an object
+// holding the schema version and an array of hands:
+//
+//	{"schema_version": 1, "hands": [<hand>, ...]}
+//
+// An NDJSON stream holds one <hand> object per line. Each hand has the following fields:
+//
+//	id            string   hand number assigned by the site
+//	site          string   site the hand was played on, e.g. "PokerStars"
+//	date          string   start time of the hand in UTC, RFC 3339
+//	button_seat   number   seat number of the button
+//	players       array    players ordered by seat: {username, seat, chip_count, cards}
+//	actions       array    actions in the order they were made: {order, player, street, type, amount}
+//	pot           number   total pot
+//	rake          number   rake taken from the pot
+//	boards        array    community cards of each board dealt, an array of up to 5 cards per board
+//	winners       array    {player, amount, board}, board is 0 when the hand ended before the flop
+//	warnings      array    strings, the lines the parser did not recognise or skipped, omitted when there are none
+//
+// cards is an empty array when a player's hole cards are unknown. street is one of "preflop", "flop", "turn" or
+// "river", and type is one of "fold", "check", "call", "bet", "raise" or "post". Amounts are in the currency of
+// the table. Each warning has the form `file:line: hand <id>: kind: error: "line"`, where kind is one of the
+// hands.ParseErrorKind values and the hand and line are left out when unknown.
+//
+// Fields added to the schema without changing the meaning of existing ones, such as warnings, keep SchemaVersion,
+// so readers should ignore fields they do not know.
+//
+// # CSV layout
+//
+// The CSV encoder writes four relational tables, each with a header row, joined on hand_id:
+//
+//	hands.csv     hand_id, site, date, button_seat, pot, rake, board_1, board_2
+//	players.csv   hand_id, seat, username, chip_count, card_1, card_2
+//	actions.csv   hand_id, order, street, player, action_type, amount
+//	winners.csv   hand_id, player, amount, board
+//
+// Boards are written as space separated cards, and unknown cards as empty fields.
+//
+// # Open Hand History
+//
+// The OHH format writes one Open Hand History document per line, see package ohh.
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"pokerhud/hands"
+	"pokerhud/ohh"
+)
+
+// Format names accepted by NewEncoder and Create
+const (
+	FormatJSON    = "json"
+	FormatNDJSON  = "ndjson"
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+	FormatOHH     = "ohh"
+)
+
+// Encoder writes hands to an underlying output. Close must be called once all hands have been encoded.
+type Encoder interface {
+	Encode(h hands.Hand) error
+	Close() error
+}
+
+// NewEncoder returns an Encoder for a single stream format that writes to w.
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatJSON:
+		return NewJSONEncoder(w), nil
+	case FormatNDJSON:
+		return NewNDJSONEncoder(w), nil
+	case FormatOHH:
+		return ohh.NewEncoder(w), nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+// Create returns an Encoder for format that writes to path. For formats made of several tables, CSV and Parquet,
+// path is a directory that is created if required, otherwise it is a file. Closing the Encoder closes the files
+// created.
+func Create(format, path string) (Encoder, error) {
+	switch format {
+	case FormatCSV:
+		return CreateCSVEncoder(path)
+	case FormatParquet:
+		return CreateParquetEncoder(path)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	enc, err := NewEncoder(format, file)
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		return nil, err
+	}
+
+	return fileEncoder{enc, []io.Closer{file}}, nil
+}
+
+// fileEncoder is an Encoder that closes the files it writes to once encoding has finished.
+type fileEncoder struct {
+	Encoder
+	files []io.Closer
+}
+
+// Close terminates the encoding and closes every file, returning the first error encountered.
+func (e fileEncoder) Close() error {
+	err := e.Encoder.Close()
+	for _, f := range e.files {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,168 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"pokerhud/hands"
+	"time"
+)
+
+// SchemaVersion is the version of the JSON hand schema written by the JSON and NDJSON encoders.
+const SchemaVersion = 1
+
+type jsonHand struct {
+	ID         string         `json:"id"`
+	Site       string         `json:"site"`
+	Date       string         `json:"date"`
+	ButtonSeat int            `json:"button_seat"`
+	Players    []jsonPlayer   `json:"players"`
+	Actions    []jsonAction   `json:"actions"`
+	Pot        float64        `json:"pot"`
+	Rake       float64        `json:"rake"`
+	Boards     [][]hands.Card `json:"boards"`
+	Winners    []jsonWinner   `json:"winners"`
+	Warnings   []string       `json:"warnings,omitempty"` // unrecognised lines, and lines skipped by lenient parsing
+}
+
+type jsonPlayer struct {
+	Username  string       `json:"username"`
+	Seat      int          `json:"seat"`
+	ChipCount float64      `json:"chip_count"`
+	Cards     []hands.Card `json:"cards"`
+}
+
+type jsonAction struct {
+	Order  int              `json:"order"`
+	Player string           `json:"player"`
+	Street hands.Street     `json:"street"`
+	Type   hands.ActionType `json:"type"`
+	Amount float64          `json:"amount"`
+}
+
+type jsonWinner struct {
+	Player string  `json:"player"`
+	Amount float64 `json:"amount"`
+	Board  int     `json:"board"`
+}
+
+func toJSONHand(h hands.Hand) jsonHand {
+	jh := jsonHand{
+		ID:         h.Metadata.ID,
+		Site:       h.Metadata.Site,
+		Date:       h.Metadata.Date.UTC().Format(time.RFC3339),
+		ButtonSeat: h.Metadata.ButtonSeat,
+		Players:    make([]jsonPlayer, len(h.Players)),
+		Actions:    make([]jsonAction, len(h.Actions)),
+		Pot:        h.Summary.Pot,
+		Rake:       h.Summary.Rake,
+		Boards:     [][]hands.Card{},
+		Winners:    make([]jsonWinner, len(h.Summary.Winners)),
+	}
+
+	for i, p := range h.Players {
+		jh.Players[i] = jsonPlayer{p.Username, p.Seat, p.ChipCount, knownCards(p.Cards[:])}
+	}
+
+	for i, a := range h.Actions {
+		jh.Actions[i] = jsonAction{a.Order, a.PlayerName, a.Street, a.ActionType, a.Amount}
+	}
+
+	for _, b := range h.Summary.CommunityCards {
+		if board := boardCards(b); len(board) > 0 {
+			jh.Boards = append(jh.Boards, board)
+		}
+	}
+
+	for i, w := range h.Summary.Winners {
+		jh.Winners[i] = jsonWinner{w.PlayerName, w.Amount, w.Board}
+	}
+
+	for _, w := range h.Warnings {
+		jh.Warnings = append(jh.Warnings, w.Error())
+	}
+
+	return jh
+}
+
+// MarshalHand returns h encoded as a single JSON hand object of the documented schema.
+func MarshalHand(h hands.Hand) ([]byte, error) {
+	return json.Marshal(toJSONHand(h))
+}
+
+// knownCards returns cards without the empty entries of cards that were not seen.
+func knownCards(cards []hands.Card) []hands.Card {
+	known := make([]hands.Card, 0, len(cards))
+	for _, c := range cards {
+		if c != "" {
+			known = append(known, c)
+		}
+	}
+	return known
+}
+
+// boardCards flattens a board into the cards dealt, in the order they were dealt.
+func boardCards(cc hands.CommunityCards) []hands.Card {
+	return knownCards([]hands.Card{cc.Flop[0], cc.Flop[1], cc.Flop[2], cc.Turn, cc.River})
+}
+
+// JSONEncoder writes hands as a single JSON document. Hands are streamed to the writer as they are encoded.
+type JSONEncoder struct {
+	w     io.Writer
+	count int
+}
+
+// NewJSONEncoder returns a JSONEncoder that writes to w.
+func NewJSONEncoder(w io.Writer) *JSONEncoder {
+	return &JSONEncoder{w: w}
+}
+
+// Encode writes h to the hands array of the document.
+func (e *JSONEncoder) Encode(h hands.Hand) error {
+	data, err := MarshalHand(h)
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n"
+	if e.count == 0 {
+		sep = fmt.Sprintf("{\"schema_version\":%d,\"hands\":[\n", SchemaVersion)
+	}
+	e.count++
+
+	if _, err := io.WriteString(e.w, sep); err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+// Close terminates the JSON document. It does not close the underlying writer.
+func (e *JSONEncoder) Close() error {
+	if e.count == 0 {
+		_, err := fmt.Fprintf(e.w, "{\"schema_version\":%d,\"hands\":[]}\n", SchemaVersion)
+		return err
+	}
+	_, err := io.WriteString(e.w, "\n]}\n")
+	return err
+}
+
+// NDJSONEncoder writes hands as newline-delimited JSON, one hand object per line.
+type NDJSONEncoder struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONEncoder returns an NDJSONEncoder that writes to w.
+func NewNDJSONEncoder(w io.Writer) *NDJSONEncoder {
+	return &NDJSONEncoder{enc: json.NewEncoder(w)}
+}
+
+// Encode writes h as a single line of JSON.
+func (e *NDJSONEncoder) Encode(h hands.Hand) error {
+	return e.enc.Encode(toJSONHand(h))
+}
+
+// Close does nothing, NDJSON needs no terminator. It does not close the underlying writer.
+func (e *NDJSONEncoder) Close() error {
+	return nil
+}
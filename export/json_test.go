@@ -0,0 +1,139 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"pokerhud/hands"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNDJSONEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewNDJSONEncoder(&buf)
+
+	for _, h := range []hands.Hand{testHand, testHand} {
+		if err := enc.Encode(h); err != nil {
+			t.Fatalf("unexpected error encoding hand: %v", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("unexpected error closing encoder: %v", err)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	lines := 0
+	for scanner.Scan() {
+		lines++
+		var got map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &got); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v", lines, err)
+		}
+
+		if got["id"] != "254446123323" {
+			t.Errorf("wanted id 254446123323 but got %v", got["id"])
+		}
+	}
+
+	if lines != 2 {
+		t.Errorf("wanted 2 lines but got %d", lines)
+	}
+}
+
+func TestJSONEncoder(t *testing.T) {
+
+	t.Run("hands are written with the documented schema", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewJSONEncoder(&buf)
+
+		if err := enc.Encode(testHand); err != nil {
+			t.Fatalf("unexpected error encoding hand: %v", err)
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("unexpected error closing encoder: %v", err)
+		}
+
+		var got struct {
+			SchemaVersion int        `json:"schema_version"`
+			Hands         []jsonHand `json:"hands"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+		}
+
+		if got.SchemaVersion != SchemaVersion {
+			t.Errorf("wanted schema version %d but got %d", SchemaVersion, got.SchemaVersion)
+		}
+
+		want := jsonHand{
+			ID:         "254446123323",
+			Site:       hands.SitePokerStars,
+			Date:       "2025-01-19T12:38:55Z",
+			ButtonSeat: 1,
+			Players: []jsonPlayer{
+				{"maximoIV", 1, 5.2, []hands.Card{}},
+				{"pernadao1599", 6, 3.43, []hands.Card{"Jh", "Qc"}},
+			},
+			Actions: []jsonAction{
+				{1, "pernadao1599", hands.Preflop, hands.ActionPost, 0.02},
+				{2, "maximoIV", hands.Preflop, hands.ActionPost, 0.05},
+				{3, "pernadao1599", hands.Flop, hands.ActionBet, 0.1},
+				{4, "maximoIV", hands.Flop, hands.ActionFold, 0},
+			},
+			Pot:     0.22,
+			Rake:    0.01,
+			Boards:  [][]hands.Card{{"2h", "Ts", "Jc"}},
+			Winners: []jsonWinner{{"pernadao1599", 0.21, 1}},
+		}
+
+		if len(got.Hands) != 1 || !reflect.DeepEqual(got.Hands[0], want) {
+			t.Errorf("wanted %#v but got %#v", want, got.Hands)
+		}
+	})
+
+	t.Run("no hands is still a valid document", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewJSONEncoder(&buf)
+
+		if err := enc.Close(); err != nil {
+			t.Fatalf("unexpected error closing encoder: %v", err)
+		}
+
+		if !json.Valid(buf.Bytes()) {
+			t.Errorf("wanted valid JSON but got %s", buf.String())
+		}
+	})
+}
+
+func TestNewEncoder(t *testing.T) {
+	if _, err := NewEncoder("xml", &bytes.Buffer{}); err == nil {
+		t.Error("expected an error for an unknown format but didn't get one")
+	}
+}
+
+var testHand = hands.Hand{
+	Metadata: hands.Metadata{
+		ID:         "254446123323",
+		Date:       time.Date(2025, 1, 19, 12, 38, 55, 0, time.UTC),
+		ButtonSeat: 1,
+		Site:       hands.SitePokerStars,
+	},
+	Players: []hands.Player{
+		{Username: "maximoIV", Seat: 1, ChipCount: 5.2},
+		{Username: "pernadao1599", Cards: [2]hands.Card{"Jh", "Qc"}, Seat: 6, ChipCount: 3.43},
+	},
+	Actions: []hands.Action{
+		{PlayerName: "pernadao1599", Order: 1, Street: hands.Preflop, ActionType: hands.ActionPost, Amount: 0.02},
+		{PlayerName: "maximoIV", Order: 2, Street: hands.Preflop, ActionType: hands.ActionPost, Amount: 0.05},
+		{PlayerName: "pernadao1599", Order: 3, Street: hands.Flop, ActionType: hands.ActionBet, Amount: 0.1},
+		{PlayerName: "maximoIV", Order: 4, Street: hands.Flop, ActionType: hands.ActionFold, Amount: 0},
+	},
+	Summary: hands.Summary{
+		CommunityCards: [2]hands.CommunityCards{{Flop: [3]hands.Card{"2h", "Ts", "Jc"}}, {}},
+		Pot:            0.22,
+		Rake:           0.01,
+		Winners:        []hands.Winner{{PlayerName: "pernadao1599", Amount: 0.21, Board: 1}},
+	},
+}
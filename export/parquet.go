@@ -0,0 +1,405 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"pokerhud/hands"
+)
+
+// Parquet table file names written by CreateParquetEncoder
+const (
+	HandsParquet   = "hands.parquet"
+	ActionsParquet = "actions.parquet"
+)
+
+// defaultRowGroupRows is the number of rows buffered per table before a row group is written out. It bounds the
+// memory used by the ParquetEncoder regardless of how many hands are exported.
+const defaultRowGroupRows = 65536
+
+// decimalScale is the number of decimal places kept for amounts, stored as DECIMAL(18, 2).
+const (
+	decimalScale     = 2
+	decimalPrecision = 18
+)
+
+var parquetMagic = []byte("PAR1")
+
+// Parquet physical types, converted types and encodings, as defined by parquet.thrift
+const (
+	parquetInt32     int32 = 1
+	parquetInt64     int32 = 2
+	parquetByteArray int32 = 6
+
+	convertedUTF8            int32 = 0
+	convertedEnum            int32 = 4
+	convertedDecimal         int32 = 5
+	convertedTimestampMicros int32 = 10
+
+	encodingPlain int32 = 0
+	encodingRLE   int32 = 3
+
+	repetitionRequired int32 = 0
+)
+
+// columnKind is the logical type of a Parquet column.
+type columnKind int
+
+const (
+	kindString columnKind = iota
+	kindEnum
+	kindInt32
+	kindDecimal
+	kindTimestamp
+)
+
+// parquetColumn holds the PLAIN encoded values of a required column for the row group being built.
+type parquetColumn struct {
+	name   string
+	kind   columnKind
+	values bytes.Buffer
+}
+
+func (c *parquetColumn) physicalType() int32 {
+	switch c.kind {
+	case kindInt32:
+		return parquetInt32
+	case kindDecimal, kindTimestamp:
+		return parquetInt64
+	default:
+		return parquetByteArray
+	}
+}
+
+func (c *parquetColumn) appendString(s string) {
+	c.values.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(s))))
+	c.values.WriteString(s)
+}
+
+func (c *parquetColumn) appendInt32(v int) {
+	c.values.Write(binary.LittleEndian.AppendUint32(nil, uint32(int32(v))))
+}
+
+func (c *parquetColumn) appendInt64(v int64) {
+	c.values.Write(binary.LittleEndian.AppendUint64(nil, uint64(v)))
+}
+
+func (c *parquetColumn) appendDecimal(amount float64) {
+	c.appendInt64(int64(math.Round(amount * math.Pow10(decimalScale))))
+}
+
+type columnChunkMeta struct {
+	offset int64
+	size   int64
+}
+
+type rowGroupMeta struct {
+	columns []columnChunkMeta
+	rows    int64
+	size    int64
+}
+
+// parquetTable writes a single Parquet file of required columns, one row group at a time.
+type parquetTable struct {
+	w            io.Writer
+	offset       int64
+	columns      []*parquetColumn
+	rows         int
+	rowGroupRows int
+	groups       []rowGroupMeta
+}
+
+func newParquetTable(w io.Writer, columns []*parquetColumn) (*parquetTable, error) {
+	t := &parquetTable{w: w, columns: columns, rowGroupRows: defaultRowGroupRows}
+	return t, t.write(parquetMagic)
+}
+
+func (t *parquetTable) write(p []byte) error {
+	n, err := t.w.Write(p)
+	t.offset += int64(n)
+	return err
+}
+
+// endRow marks the values appended to every column as a complete row, writing out a row group when it is full.
+func (t *parquetTable) endRow() error {
+	t.rows++
+	if t.rows >= t.rowGroupRows {
+		return t.flush()
+	}
+	return nil
+}
+
+// flush writes the buffered rows as a row group holding a single data page per column.
+func (t *parquetTable) flush() error {
+	if t.rows == 0 {
+		return nil
+	}
+
+	group := rowGroupMeta{rows: int64(t.rows)}
+	for _, c := range t.columns {
+		header := dataPageHeader(c.values.Len(), t.rows)
+		chunk := columnChunkMeta{offset: t.offset, size: int64(len(header) + c.values.Len())}
+
+		if err := t.write(header); err != nil {
+			return err
+		}
+		if err := t.write(c.values.Bytes()); err != nil {
+			return err
+		}
+
+		c.values.Reset()
+		group.columns = append(group.columns, chunk)
+		group.size += chunk.size
+	}
+
+	t.groups = append(t.groups, group)
+	t.rows = 0
+	return nil
+}
+
+// close writes any buffered rows followed by the file footer. It does not close the underlying writer.
+func (t *parquetTable) close() error {
+	if err := t.flush(); err != nil {
+		return err
+	}
+
+	footer := t.fileMetadata()
+	if err := t.write(footer); err != nil {
+		return err
+	}
+	if err := t.write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer)))); err != nil {
+		return err
+	}
+	return t.write(parquetMagic)
+}
+
+func dataPageHeader(pageSize, numValues int) []byte {
+	w := newThriftWriter()
+	w.i32(1, 0) // DATA_PAGE
+	w.i32(2, int32(pageSize))
+	w.i32(3, int32(pageSize))
+	w.structBegin(5)
+	w.i32(1, int32(numValues))
+	w.i32(2, encodingPlain)
+	w.i32(3, encodingRLE)
+	w.i32(4, encodingRLE)
+	w.structEnd()
+	return w.finish()
+}
+
+func (t *parquetTable) fileMetadata() []byte {
+	var numRows int64
+	for _, g := range t.groups {
+		numRows += g.rows
+	}
+
+	w := newThriftWriter()
+	w.i32(1, 1) // version
+
+	w.listBegin(2, thriftStruct, len(t.columns)+1)
+	w.structBegin(-1)
+	w.string(4, "schema")
+	w.i32(5, int32(len(t.columns)))
+	w.structEnd()
+	for _, c := range t.columns {
+		writeSchemaElement(w, c)
+	}
+
+	w.i64(3, numRows)
+
+	w.listBegin(4, thriftStruct, len(t.groups))
+	for _, g := range t.groups {
+		w.structBegin(-1)
+		w.listBegin(1, thriftStruct, len(g.columns))
+		for i, chunk := range g.columns {
+			writeColumnChunk(w, t.columns[i], chunk, g.rows)
+		}
+		w.i64(2, g.size)
+		w.i64(3, g.rows)
+		w.structEnd()
+	}
+
+	w.string(6, "holdem-analytics")
+	return w.finish()
+}
+
+func writeSchemaElement(w *thriftWriter, c *parquetColumn) {
+	w.structBegin(-1)
+	w.i32(1, c.physicalType())
+	w.i32(3, repetitionRequired)
+	w.string(4, c.name)
+
+	switch c.kind {
+	case kindString:
+		w.i32(6, convertedUTF8)
+		w.structBegin(10)
+		w.emptyStruct(1) // STRING
+		w.structEnd()
+	case kindEnum:
+		w.i32(6, convertedEnum)
+		w.structBegin(10)
+		w.emptyStruct(4) // ENUM
+		w.structEnd()
+	case kindDecimal:
+		w.i32(6, convertedDecimal)
+		w.i32(7, decimalScale)
+		w.i32(8, decimalPrecision)
+		w.structBegin(10)
+		w.structBegin(5) // DECIMAL
+		w.i32(1, decimalScale)
+		w.i32(2, decimalPrecision)
+		w.structEnd()
+		w.structEnd()
+	case kindTimestamp:
+		w.i32(6, convertedTimestampMicros)
+		w.structBegin(10)
+		w.structBegin(8) // TIMESTAMP
+		w.bool(1, true)  // isAdjustedToUTC
+		w.structBegin(2)
+		w.emptyStruct(2) // MICROS
+		w.structEnd()
+		w.structEnd()
+		w.structEnd()
+	}
+
+	w.structEnd()
+}
+
+func writeColumnChunk(w *thriftWriter, c *parquetColumn, chunk columnChunkMeta, rows int64) {
+	w.structBegin(-1)
+	w.i64(2, chunk.offset)
+
+	w.structBegin(3)
+	w.i32(1, c.physicalType())
+	w.listBegin(2, thriftI32, 2)
+	w.i32ListElem(encodingPlain)
+	w.i32ListElem(encodingRLE)
+	w.listBegin(3, thriftBinary, 1)
+	w.stringListElem(c.name)
+	w.i32(4, 0) // UNCOMPRESSED
+	w.i64(5, rows)
+	w.i64(6, chunk.size)
+	w.i64(7, chunk.size)
+	w.i64(9, chunk.offset)
+	w.structEnd()
+
+	w.structEnd()
+}
+
+// ParquetEncoder writes hands as two Parquet tables, hands and actions, joined on hand_id. Rows are written out in
+// row groups as hands are encoded, so memory use stays bounded however many hands are exported.
+//
+// The hands table has the columns hand_id, site, date (timestamp, UTC), button_seat, player_count, pot and rake
+// (DECIMAL(18, 2)), board_1 and board_2 (space separated cards). The actions table has the columns hand_id, order,
+// street (enum), player, action_type (enum) and amount (DECIMAL(18, 2)).
+type ParquetEncoder struct {
+	hands   *parquetTable
+	actions *parquetTable
+}
+
+// NewParquetEncoder returns a ParquetEncoder writing the hands table to handsW and the actions table to actionsW.
+func NewParquetEncoder(handsW, actionsW io.Writer) (*ParquetEncoder, error) {
+	handsTable, err := newParquetTable(handsW, []*parquetColumn{
+		{name: "hand_id", kind: kindString},
+		{name: "site", kind: kindString},
+		{name: "date", kind: kindTimestamp},
+		{name: "button_seat", kind: kindInt32},
+		{name: "player_count", kind: kindInt32},
+		{name: "pot", kind: kindDecimal},
+		{name: "rake", kind: kindDecimal},
+		{name: "board_1", kind: kindString},
+		{name: "board_2", kind: kindString},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	actionsTable, err := newParquetTable(actionsW, []*parquetColumn{
+		{name: "hand_id", kind: kindString},
+		{name: "order", kind: kindInt32},
+		{name: "street", kind: kindEnum},
+		{name: "player", kind: kindString},
+		{name: "action_type", kind: kindEnum},
+		{name: "amount", kind: kindDecimal},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ParquetEncoder{handsTable, actionsTable}, nil
+}
+
+// CreateParquetEncoder creates the directory dir if required and returns a ParquetEncoder writing the tables to
+// files within it. Closing the encoder closes the files.
+func CreateParquetEncoder(dir string) (Encoder, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+
+	handsFile, err := os.Create(filepath.Join(dir, HandsParquet))
+	if err != nil {
+		return nil, err
+	}
+
+	actionsFile, err := os.Create(filepath.Join(dir, ActionsParquet))
+	if err != nil {
+		handsFile.Close()
+		return nil, err
+	}
+
+	enc, err := NewParquetEncoder(handsFile, actionsFile)
+	if err != nil {
+		handsFile.Close()
+		actionsFile.Close()
+		return nil, err
+	}
+
+	return fileEncoder{enc, []io.Closer{handsFile, actionsFile}}, nil
+}
+
+// Encode appends a row for h to the hands table and a row per action to the actions table.
+func (e *ParquetEncoder) Encode(h hands.Hand) error {
+	boards := h.Summary.CommunityCards
+
+	hc := e.hands.columns
+	hc[0].appendString(h.Metadata.ID)
+	hc[1].appendString(h.Metadata.Site)
+	hc[2].appendInt64(h.Metadata.Date.UnixMicro())
+	hc[3].appendInt32(h.Metadata.ButtonSeat)
+	hc[4].appendInt32(len(h.Players))
+	hc[5].appendDecimal(h.Summary.Pot)
+	hc[6].appendDecimal(h.Summary.Rake)
+	hc[7].appendString(joinCards(boardCards(boards[0])))
+	hc[8].appendString(joinCards(boardCards(boards[1])))
+
+	if err := e.hands.endRow(); err != nil {
+		return err
+	}
+
+	ac := e.actions.columns
+	for _, a := range h.Actions {
+		ac[0].appendString(h.Metadata.ID)
+		ac[1].appendInt32(a.Order)
+		ac[2].appendString(string(a.Street))
+		ac[3].appendString(a.PlayerName)
+		ac[4].appendString(a.ActionType.String())
+		ac[5].appendDecimal(a.Amount)
+
+		if err := e.actions.endRow(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close writes the remaining rows and the footer of each table. It does not close the underlying writers.
+func (e *ParquetEncoder) Close() error {
+	if err := e.hands.close(); err != nil {
+		return err
+	}
+	return e.actions.close()
+}
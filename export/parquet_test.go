@@ -0,0 +1,331 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestThriftWriter(t *testing.T) {
+	w := newThriftWriter()
+	w.i32(1, 3)
+	w.i32(20, -1)
+	w.string(21, "ab")
+	w.structBegin(22)
+	w.bool(1, true)
+	w.structEnd()
+
+	got := w.finish()
+	want := []byte{
+		0x15, 0x06, // field 1, i32, zigzag 3
+		0x05, 0x28, 0x01, // field 20 (long form), i32, zigzag -1
+		0x18, 0x02, 'a', 'b', // field 21, binary
+		0x1c, 0x11, 0x00, // field 22, struct {field 1 true}
+		0x00, // stop
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("wanted % x but got % x", want, got)
+	}
+}
+
+func TestParquetEncoder(t *testing.T) {
+	var handsBuf, actionsBuf bytes.Buffer
+	enc, err := NewParquetEncoder(&handsBuf, &actionsBuf)
+	if err != nil {
+		t.Fatalf("unexpected error creating encoder: %v", err)
+	}
+	enc.hands.rowGroupRows = 2
+	enc.actions.rowGroupRows = 2
+
+	for range 5 {
+		if err := enc.Encode(testHand); err != nil {
+			t.Fatalf("unexpected error encoding hand: %v", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("unexpected error closing encoder: %v", err)
+	}
+
+	cases := []struct {
+		name       string
+		table      *parquetTable
+		data       []byte
+		wantGroups int
+		wantRows   int64
+	}{
+		{"hands", enc.hands, handsBuf.Bytes(), 3, 5},
+		{"actions", enc.actions, actionsBuf.Bytes(), 10, 20},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if !bytes.HasPrefix(tt.data, parquetMagic) || !bytes.HasSuffix(tt.data, parquetMagic) {
+				t.Fatal("wanted the file to start and end with PAR1")
+			}
+
+			footerLen := binary.LittleEndian.Uint32(tt.data[len(tt.data)-8:])
+			if int(footerLen) >= len(tt.data)-12 {
+				t.Errorf("footer length %d is longer than the file", footerLen)
+			}
+
+			if len(tt.table.groups) != tt.wantGroups {
+				t.Errorf("wanted %d row groups but got %d", tt.wantGroups, len(tt.table.groups))
+			}
+
+			var rows int64
+			for _, g := range tt.table.groups {
+				rows += g.rows
+			}
+			if rows != tt.wantRows {
+				t.Errorf("wanted %d rows but got %d", tt.wantRows, rows)
+			}
+		})
+	}
+}
+
+func TestParquetEncoderReadBack(t *testing.T) {
+	var handsBuf, actionsBuf bytes.Buffer
+	enc, err := NewParquetEncoder(&handsBuf, &actionsBuf)
+	if err != nil {
+		t.Fatalf("unexpected error creating encoder: %v", err)
+	}
+	enc.hands.rowGroupRows = 2
+	enc.actions.rowGroupRows = 3
+
+	const handCount = 5
+	var wantHands, wantActions [][]any
+	for range handCount {
+		if err := enc.Encode(testHand); err != nil {
+			t.Fatalf("unexpected error encoding hand: %v", err)
+		}
+
+		h := testHand
+		wantHands = append(wantHands, []any{h.Metadata.ID, h.Metadata.Site, h.Metadata.Date.UnixMicro(), int64(1), int64(2),
+			int64(22), int64(1), "2h Ts Jc", ""})
+		for _, a := range h.Actions {
+			wantActions = append(wantActions, []any{h.Metadata.ID, int64(a.Order), string(a.Street), a.PlayerName,
+				a.ActionType.String(), int64(math.Round(a.Amount * 100))})
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("unexpected error closing encoder: %v", err)
+	}
+
+	cases := []struct {
+		name        string
+		data        []byte
+		wantColumns []string
+		wantRows    [][]any
+	}{
+		{"hands", handsBuf.Bytes(), []string{"hand_id", "site", "date", "button_seat", "player_count", "pot", "rake", "board_1", "board_2"}, wantHands},
+		{"actions", actionsBuf.Bytes(), []string{"hand_id", "order", "street", "player", "action_type", "amount"}, wantActions},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, rows := readParquet(t, tt.data)
+
+			if !slices.Equal(columns, tt.wantColumns) {
+				t.Errorf("wanted the columns %v but got %v", tt.wantColumns, columns)
+			}
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("wanted the rows\n%v\nbut got\n%v", tt.wantRows, rows)
+			}
+		})
+	}
+}
+
+// readParquet reads back a file written by a parquetTable, returning its column names and rows. The footer is
+// decoded and checked against the row groups and pages it describes, and the PLAIN encoded values of each page are
+// decoded by the column's physical type, as a Parquet reader would.
+func readParquet(t *testing.T, data []byte) ([]string, [][]any) {
+	t.Helper()
+	if !bytes.HasPrefix(data, parquetMagic) || !bytes.HasSuffix(data, parquetMagic) {
+		t.Fatal("wanted the file to start and end with PAR1")
+	}
+
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footerStart := len(data) - 8 - footerLen
+	footer, n := readThriftStruct(t, data[footerStart:])
+	if n != footerLen {
+		t.Fatalf("wanted a footer of %d bytes but decoded %d", footerLen, n)
+	}
+
+	if footer[1] != int64(1) {
+		t.Errorf("wanted version 1 but got %v", footer[1])
+	}
+
+	schema := footer[2].([]any)
+	root := schema[0].(thriftFields)
+	if string(root[4].([]byte)) != "schema" || root[5] != int64(len(schema)-1) {
+		t.Errorf("wanted a root schema element with %d children but got %v", len(schema)-1, root)
+	}
+
+	var columns []string
+	var types []int64
+	for _, e := range schema[1:] {
+		element := e.(thriftFields)
+		if element[3] != int64(repetitionRequired) {
+			t.Errorf("wanted required columns but got %v", element)
+		}
+		columns = append(columns, string(element[4].([]byte)))
+		types = append(types, element[1].(int64))
+	}
+
+	var rows [][]any
+	for _, g := range footer[4].([]any) {
+		group := g.(thriftFields)
+		groupRows := group[3].(int64)
+		firstRow := len(rows)
+		for range groupRows {
+			rows = append(rows, make([]any, len(columns)))
+		}
+
+		var groupSize int64
+		for i, c := range group[1].([]any) {
+			meta := c.(thriftFields)[3].(thriftFields)
+			path := meta[3].([]any)
+			if meta[1] != types[i] || len(path) != 1 || string(path[0].([]byte)) != columns[i] || meta[5] != groupRows {
+				t.Fatalf("wanted the metadata of %d %s values but got %v", groupRows, columns[i], meta)
+			}
+
+			offset, size := meta[9].(int64), meta[7].(int64)
+			header, n := readThriftStruct(t, data[offset:])
+			page := header[5].(thriftFields)
+			pageSize := header[3].(int64)
+			if header[1] != int64(0) || page[1] != groupRows || page[2] != int64(encodingPlain) || int64(n)+pageSize != size {
+				t.Fatalf("wanted a PLAIN data page of %d values and %d bytes but got %v", groupRows, size, header)
+			}
+			groupSize += size
+
+			values := data[offset+int64(n) : offset+size]
+			for r := range groupRows {
+				var v any
+				switch int32(types[i]) {
+				case parquetInt32:
+					v, values = int64(int32(binary.LittleEndian.Uint32(values))), values[4:]
+				case parquetInt64:
+					v, values = int64(binary.LittleEndian.Uint64(values)), values[8:]
+				case parquetByteArray:
+					l := binary.LittleEndian.Uint32(values)
+					v, values = string(values[4:4+l]), values[4+l:]
+				}
+				rows[firstRow+int(r)][i] = v
+			}
+			if len(values) != 0 {
+				t.Errorf("wanted every value of the %s page decoded but %d bytes are left", columns[i], len(values))
+			}
+		}
+
+		if group[2] != groupSize {
+			t.Errorf("wanted a row group of %d bytes but got %v", groupSize, group[2])
+		}
+	}
+
+	if footer[3] != int64(len(rows)) {
+		t.Errorf("wanted %d rows but the footer has %v", len(rows), footer[3])
+	}
+	return columns, rows
+}
+
+// thriftFields is a decoded Thrift struct, holding its fields by id. Integers are decoded as int64, binary fields as
+// []byte, lists as []any and structs as thriftFields.
+type thriftFields map[int16]any
+
+// readThriftStruct decodes a Thrift compact protocol struct from the start of data, returning it along with the number
+// of bytes it took up.
+func readThriftStruct(t *testing.T, data []byte) (thriftFields, int) {
+	t.Helper()
+	r := &thriftReader{t: t, data: data}
+	return r.readStruct(), r.pos
+}
+
+// thriftReader decodes the subset of the Thrift compact protocol written by thriftWriter.
+type thriftReader struct {
+	t    *testing.T
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) byte() byte {
+	if r.pos >= len(r.data) {
+		r.t.Fatal("unexpected end of thrift data")
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		r.t.Fatalf("invalid varint at %d", r.pos)
+	}
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) varint() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1) // zigzag
+}
+
+func (r *thriftReader) readStruct() thriftFields {
+	s := thriftFields{}
+	var id int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return s
+		}
+
+		typ := header & 0x0f
+		if delta := int16(header >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.varint())
+		}
+
+		switch typ {
+		case thriftBoolTrue:
+			s[id] = true
+		case thriftBoolFalse:
+			s[id] = false
+		default:
+			s[id] = r.readValue(typ)
+		}
+	}
+}
+
+func (r *thriftReader) readValue(typ byte) any {
+	switch typ {
+	case thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		l := int(r.uvarint())
+		if r.pos+l > len(r.data) {
+			r.t.Fatal("unexpected end of thrift data")
+		}
+		r.pos += l
+		return r.data[r.pos-l : r.pos]
+	case thriftStruct:
+		return r.readStruct()
+	case thriftList:
+		header := r.byte()
+		size, elemType := int(header>>4), header&0x0f
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.readValue(elemType)
+		}
+		return list
+	}
+	r.t.Fatalf("unsupported thrift type %d at %d", typ, r.pos)
+	return nil
+}
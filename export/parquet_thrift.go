@@ -0,0 +1,115 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol field types
+const (
+	thriftBoolTrue  byte = 1
+	thriftBoolFalse byte = 2
+	thriftI32       byte = 5
+	thriftI64       byte = 6
+	thriftBinary    byte = 8
+	thriftList      byte = 9
+	thriftStruct    byte = 12
+)
+
+// thriftWriter encodes the subset of the Thrift compact protocol needed to write Parquet page headers and file
+// metadata.
+type thriftWriter struct {
+	buf       bytes.Buffer
+	lastField []int16 // last field id written, per nested struct
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{lastField: []int16{0}}
+}
+
+func (w *thriftWriter) uvarint(v uint64) {
+	w.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func (w *thriftWriter) varint(v int64) {
+	w.uvarint(uint64((v << 1) ^ (v >> 63))) // zigzag
+}
+
+func (w *thriftWriter) fieldHeader(id int16, typ byte) {
+	last := &w.lastField[len(w.lastField)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.varint(int64(id))
+	}
+	*last = id
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.fieldHeader(id, thriftI32)
+	w.varint(int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.fieldHeader(id, thriftI64)
+	w.varint(v)
+}
+
+func (w *thriftWriter) bool(id int16, v bool) {
+	if v {
+		w.fieldHeader(id, thriftBoolTrue)
+	} else {
+		w.fieldHeader(id, thriftBoolFalse)
+	}
+}
+
+func (w *thriftWriter) string(id int16, v string) {
+	w.fieldHeader(id, thriftBinary)
+	w.uvarint(uint64(len(v)))
+	w.buf.WriteString(v)
+}
+
+// structBegin starts a struct valued field. A negative id starts a struct that is a list element.
+func (w *thriftWriter) structBegin(id int16) {
+	if id >= 0 {
+		w.fieldHeader(id, thriftStruct)
+	}
+	w.lastField = append(w.lastField, 0)
+}
+
+func (w *thriftWriter) structEnd() {
+	w.buf.WriteByte(0) // stop field
+	w.lastField = w.lastField[:len(w.lastField)-1]
+}
+
+func (w *thriftWriter) listBegin(id int16, elemType byte, size int) {
+	w.fieldHeader(id, thriftList)
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		w.buf.WriteByte(0xf0 | elemType)
+		w.uvarint(uint64(size))
+	}
+}
+
+func (w *thriftWriter) i32ListElem(v int32) {
+	w.varint(int64(v))
+}
+
+func (w *thriftWriter) stringListElem(v string) {
+	w.uvarint(uint64(len(v)))
+	w.buf.WriteString(v)
+}
+
+// emptyStruct writes a struct field with no fields, as used by Thrift unions of marker types.
+func (w *thriftWriter) emptyStruct(id int16) {
+	w.structBegin(id)
+	w.structEnd()
+}
+
+// finish ends the top level struct and returns the encoded message. Every nested struct must have been ended.
+func (w *thriftWriter) finish() []byte {
+	w.buf.WriteByte(0)
+	return w.buf.Bytes()
+}
@@ -2,32 +2,88 @@ package fileutil
 
 import (
 	"errors"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"syscall"
 )
 
 // MoveProcessedFiles moves a file to specified destination under newPath. It first checks that the directory in newPath exists, and creates it if required.
 func MoveProcessedFiles(oldPath, newPath string) error {
-	found, err := checkDirExists(filepath.Dir(newPath))
+	if err := ensureDir(filepath.Dir(newPath)); err != nil {
+		return err
+	}
+
+	return moveFile(oldPath, newPath)
+}
+
+// CopyProcessedFiles copies a file to newPath, keeping its modification time, and leaves the original in place. The
+// directory in newPath is created if required.
+func CopyProcessedFiles(oldPath, newPath string) error {
+	if err := ensureDir(filepath.Dir(newPath)); err != nil {
+		return err
+	}
+
+	return copyFile(oldPath, newPath)
+}
+
+func ensureDir(dir string) error {
+	found, err := checkDirExists(dir)
 	if err != nil {
 		log.Printf("an unexpected error occurred with the specied path")
 		return err
 	}
 
 	if !found {
-		mkdirErr := os.Mkdir(filepath.Dir(newPath), 0750)
+		mkdirErr := os.MkdirAll(dir, 0750)
 		if mkdirErr != nil {
 			log.Println("could not create folder for processed hands")
 			return mkdirErr
 		}
 	}
-
-	return moveFile(oldPath, newPath)
+	return nil
 }
 
+// moveFile renames oldPath to newPath, falling back to a copy and delete when they are on different devices.
 func moveFile(oldPath, newPath string) error {
-	return os.Rename(oldPath, newPath)
+	err := os.Rename(oldPath, newPath)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	if err := copyFile(oldPath, newPath); err != nil {
+		return err
+	}
+	return os.Remove(oldPath)
+}
+
+func copyFile(oldPath, newPath string) error {
+	src, err := os.Open(oldPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(newPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Chtimes(newPath, info.ModTime(), info.ModTime())
 }
 
 func checkDirExists(path string) (bool, error) {
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestMoveProcessedFiles(t *testing.T) {
@@ -73,3 +74,34 @@ func TestCheckDirExists(t *testing.T) {
 
 	})
 }
+
+func TestCopyProcessedFiles(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "testing-123.txt")
+	newPath := filepath.Join(dir, "backup", "nested", "testing-123.txt")
+	modTime := time.Date(2025, 1, 19, 12, 38, 55, 0, time.UTC)
+
+	if err := os.WriteFile(oldPath, []byte("PokerStars Hand #1"), 0640); err != nil {
+		t.Fatalf("test setup failed: %v", err)
+	}
+	if err := os.Chtimes(oldPath, modTime, modTime); err != nil {
+		t.Fatalf("test setup failed: %v", err)
+	}
+
+	if err := CopyProcessedFiles(oldPath, newPath); err != nil {
+		t.Fatalf("wanted no error but got %v", err)
+	}
+
+	if _, err := os.Stat(oldPath); err != nil {
+		t.Errorf("wanted the original file left in place but got %v", err)
+	}
+
+	data, err := os.ReadFile(newPath)
+	if err != nil || string(data) != "PokerStars Hand #1" {
+		t.Errorf("wanted the file copied to %s but got %q, %v", newPath, data, err)
+	}
+
+	if info, err := os.Stat(newPath); err != nil || !info.ModTime().Equal(modTime) {
+		t.Errorf("wanted the copy to keep the modification time %v", modTime)
+	}
+}
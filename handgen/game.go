@@ -0,0 +1,351 @@
+package handgen
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"pokerhud/hands"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// maxRaises is the number of bets and raises made on a street before players only call or fold
+const maxRaises = 4
+
+// streets are the streets of a hand in the order they are played
+var streets = []hands.Street{hands.Preflop, hands.Flop, hands.Turn, hands.River}
+
+// player is a player in a hand being generated. Amounts are in cents.
+type player struct {
+	name   string
+	seat   int
+	stack  int // chips at the start of the hand
+	chips  int // chips left
+	cards  [2]hands.Card
+	bet    int          // chips put in on the current street
+	total  int          // chips put in over the hand
+	acted  bool         // whether the player has acted since the last bet or raise
+	allIn  bool         // whether the player has no chips left to bet
+	folded hands.Street // the street the player folded on, if they have
+	won    [2]int       // the chips won on each board
+}
+
+// game plays out a hand, writing its text and recording the hand the parser is expected to read from it as it goes.
+type game struct {
+	rng        *rand.Rand
+	buf        bytes.Buffer
+	want       hands.Hand
+	players    []*player // in seat order
+	button     int       // the index of the button in players
+	small, big int
+	deck       []hands.Card
+	boards     [2][]hands.Card // the board, and the second board if the hand is run twice
+	street     hands.Street
+	betTo      int // the highest bet on the current street
+	minRaise   int // the smallest a raise on the current street may be
+	raises     int // the bets and raises made on the current street
+}
+
+func (g *game) line(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+	g.buf.WriteByte('\n')
+}
+
+// header writes the lines describing the hand and its players.
+func (g *game) header(handID int64, date time.Time, table string, size int, zoom bool, hero string) {
+	siteTime := date.In(easternTime).Format(hands.SiteTimeLayout)
+	blinds := fmt.Sprintf("%s/%s USD", amount(g.small), amount(g.big))
+	kind := "Hand"
+	if zoom {
+		blinds = fmt.Sprintf("%s/%s", amount(g.small), amount(g.big))
+		kind = "Zoom Hand"
+	}
+
+	g.line("PokerStars %s #%d:  Hold'em No Limit (%s) - %s UTC [%s ET]",
+		kind, handID, blinds, date.UTC().Format(hands.SiteTimeLayout), siteTime)
+	g.line("Table '%s' %d-max Seat #%d is the button", table, size, g.players[g.button].seat)
+
+	for _, p := range g.players {
+		g.line("Seat %d: %s (%s in chips) ", p.seat, p.name, amount(p.stack))
+	}
+
+	// the parser reads the time PokerStars gives in ET
+	parsed, _ := time.ParseInLocation(hands.SiteTimeLayout, siteTime, easternTime)
+	g.want.Metadata = hands.Metadata{
+		ID:         strconv.FormatInt(handID, 10),
+		Date:       parsed.UTC(),
+		ButtonSeat: g.players[g.button].seat,
+		Site:       hands.SitePokerStars,
+		Table:      table,
+		Hero:       hero,
+	}
+}
+
+// play plays the hand out from the blinds to the summary.
+func (g *game) play() {
+	n := len(g.players)
+	small, big := (g.button+1)%n, (g.button+2)%n
+	if n == 2 {
+		small, big = g.button, (g.button+1)%n // heads up the button posts the small blind
+	}
+
+	g.street = hands.Preflop
+	g.post(g.players[small], "small blind", g.small)
+	g.post(g.players[big], "big blind", g.big)
+	g.betTo, g.minRaise = g.big, g.big
+
+	g.line("*** HOLE CARDS ***")
+	for _, p := range g.players {
+		p.cards = [2]hands.Card{g.draw(), g.draw()}
+		if p.name == g.want.Metadata.Hero {
+			g.line("Dealt to %s [%s %s]", p.name, p.cards[0], p.cards[1])
+		}
+	}
+
+	first := (big + 1) % n
+	for _, street := range streets {
+		if street != hands.Preflop {
+			g.startStreet(street)
+			first = (g.button + 1) % n
+		}
+
+		g.bettingRound(first)
+		g.returnUncalled()
+
+		if g.inHand() == 1 {
+			g.foldedOut()
+			return
+		}
+		if g.canAct() < 2 {
+			break // every player but one is all-in, so there is no more betting
+		}
+	}
+
+	g.runOut()
+	g.showdown()
+}
+
+// draw deals the top card of the deck
+func (g *game) draw() hands.Card {
+	c := g.deck[0]
+	g.deck = g.deck[1:]
+	return c
+}
+
+// startStreet deals the cards of street, and starts its betting.
+func (g *game) startStreet(street hands.Street) {
+	g.street = street
+	g.betTo, g.minRaise, g.raises = 0, g.big, 0
+	for _, p := range g.players {
+		p.bet, p.acted = 0, false
+	}
+
+	g.deal("", street, 0)
+}
+
+// deal deals the cards of street to board, writing the street's line with run, e.g. "FIRST ", before the street
+// when the hand is run twice.
+func (g *game) deal(run string, street hands.Street, board int) {
+	cards := 1
+	if street == hands.Flop {
+		cards = 3
+	}
+	for range cards {
+		g.boards[board] = append(g.boards[board], g.draw())
+	}
+
+	b := g.boards[board]
+	switch street {
+	case hands.Flop:
+		g.line("*** %sFLOP *** [%s %s %s]", run, b[0], b[1], b[2])
+	case hands.Turn:
+		g.line("*** %sTURN *** [%s %s %s] [%s]", run, b[0], b[1], b[2], b[3])
+	case hands.River:
+		g.line("*** %sRIVER *** [%s %s %s %s] [%s]", run, b[0], b[1], b[2], b[3], b[4])
+	}
+}
+
+// inHand returns the number of players who have not folded.
+func (g *game) inHand() int {
+	count := 0
+	for _, p := range g.players {
+		if p.folded == "" {
+			count++
+		}
+	}
+	return count
+}
+
+// canAct returns the number of players who have not folded and have chips left to bet.
+func (g *game) canAct() int {
+	count := 0
+	for _, p := range g.players {
+		if p.folded == "" && !p.allIn {
+			count++
+		}
+	}
+	return count
+}
+
+// pot returns the chips put in by every player so far.
+func (g *game) pot() int {
+	pot := 0
+	for _, p := range g.players {
+		pot += p.total
+	}
+	return pot
+}
+
+// bettingRound has players act in turn, starting from first, until the betting on the street is over.
+func (g *game) bettingRound(first int) {
+	for i := first; g.inHand() > 1; i = (i + 1) % len(g.players) {
+		if !slices.ContainsFunc(g.players, g.toAct) {
+			return
+		}
+
+		if p := g.players[i]; g.toAct(p) {
+			g.act(p)
+		}
+	}
+}
+
+// toAct reports whether p has still to act on the current street.
+func (g *game) toAct(p *player) bool {
+	if p.folded != "" || p.allIn {
+		return false
+	}
+	if p.bet < g.betTo {
+		return true
+	}
+
+	// a player matching the bet of players who are all-in has no one left to bet against
+	return !p.acted && g.canAct() > 1
+}
+
+// act has p make a random action.
+func (g *game) act(p *player) {
+	toCall := g.betTo - p.bet
+	canRaise := p.chips > toCall && g.raises < maxRaises && g.canAct() > 1
+
+	switch {
+	case toCall == 0 && canRaise && g.rng.IntN(3) == 0:
+		g.raise(p)
+	case toCall == 0:
+		g.line("%s: checks ", p.name)
+		g.record(p, hands.ActionCheck, 0)
+	case canRaise && g.rng.IntN(5) == 0:
+		g.raise(p)
+	case g.rng.IntN(2) == 0:
+		g.line("%s: folds ", p.name)
+		g.record(p, hands.ActionFold, 0)
+		p.folded = g.street
+	default:
+		call := min(toCall, p.chips)
+		g.put(p, call)
+		g.line("%s: calls %s%s", p.name, amount(call), allIn(p))
+		g.record(p, hands.ActionCall, call)
+	}
+	p.acted = true
+}
+
+// raise has p bet, or raise if there is a bet already, by a random amount that may put them all-in.
+func (g *game) raise(p *player) {
+	size := max(g.minRaise, g.pot()*(30+g.rng.IntN(90))/100)
+	to := g.betTo + size
+	if to-p.bet >= p.chips || g.rng.IntN(8) == 0 {
+		to = p.bet + p.chips
+	}
+
+	raise := to - g.betTo
+	g.minRaise = max(g.minRaise, raise)
+	g.put(p, to-p.bet)
+
+	if g.betTo == 0 {
+		g.line("%s: bets %s%s", p.name, amount(to), allIn(p))
+		g.record(p, hands.ActionBet, to)
+	} else {
+		g.line("%s: raises %s to %s%s", p.name, amount(raise), amount(to), allIn(p))
+		g.record(p, hands.ActionRaise, raise)
+	}
+
+	g.betTo = to
+	g.raises++
+	for _, other := range g.players {
+		other.acted = false
+	}
+}
+
+// post has p post a blind.
+func (g *game) post(p *player, blind string, cents int) {
+	g.put(p, cents)
+	g.line("%s: posts %s %s", p.name, blind, amount(cents))
+	g.record(p, hands.ActionPost, cents)
+}
+
+// put moves cents of p's chips into the pot.
+func (g *game) put(p *player, cents int) {
+	p.chips -= cents
+	p.bet += cents
+	p.total += cents
+	p.allIn = p.chips == 0
+}
+
+// record adds an action to the hand the parser is expected to read.
+func (g *game) record(p *player, action hands.ActionType, cents int) {
+	g.want.Actions = append(g.want.Actions, hands.Action{
+		PlayerName: p.name,
+		Order:      len(g.want.Actions) + 1,
+		Street:     g.street,
+		ActionType: action,
+		Amount:     dollars(cents),
+	})
+}
+
+func allIn(p *player) string {
+	if p.allIn {
+		return " and is all-in"
+	}
+	return ""
+}
+
+// returnUncalled returns the part of the highest bet on the street that no one matched to the player who made it.
+func (g *game) returnUncalled() {
+	top := slices.MaxFunc(g.players, func(a, b *player) int { return a.bet - b.bet })
+
+	second := 0
+	for _, p := range g.players {
+		if p != top {
+			second = max(second, p.bet)
+		}
+	}
+
+	if uncalled := top.bet - second; uncalled > 0 {
+		g.put(top, -uncalled)
+		g.line("Uncalled bet (%s) returned to %s", amount(uncalled), top.name)
+	}
+}
+
+// runOut deals the streets left once the betting is over, running them twice a third of the time.
+func (g *game) runOut() {
+	streets := streets[slices.Index(streets, g.street)+1:]
+	if len(streets) == 0 {
+		return
+	}
+
+	if g.rng.IntN(3) != 0 {
+		for _, s := range streets {
+			g.street = s
+			g.deal("", s, 0)
+		}
+		return
+	}
+
+	g.boards[1] = slices.Clone(g.boards[0])
+	for _, s := range streets {
+		g.deal("FIRST ", s, 0)
+	}
+	for _, s := range streets {
+		g.deal("SECOND ", s, 1)
+	}
+}
@@ -0,0 +1,177 @@
+// Package handgen generates PokerStars hand histories from a seed, for benchmarks and property tests.
+//
+// Every hand is generated along with the hands.Hand the parser is expected to read from it. The hands cover random
+// tables, seats, stacks and actions, all-ins with side pots and boards run twice. The same seed always generates the
+// same hands. Hole cards and boards are dealt from a shuffled deck, but pots are awarded at random rather than by
+// evaluating the players' hands.
+package handgen
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"pokerhud/hands"
+	"slices"
+	"strconv"
+	"testing/fstest"
+	"time"
+)
+
+// players is the number of usernames a Generator seats its hands from
+const players = 100
+
+var easternTime, _ = time.LoadLocation("America/New_York")
+
+var (
+	stakes     = [][2]int{{1, 2}, {2, 5}, {5, 10}, {10, 25}, {25, 50}} // small and big blinds in cents
+	tableNames = []string{"Halley", "Donati", "Wei III", "Borrelly II", "Tempel", "Swift IV", "Encke"}
+	tableSizes = []int{2, 6, 6, 6, 9}
+	nameStarts = []string{"Kav", "max", "pern", "dlou", "Chew", "gep", "Jav", "ric", "ferch", "Chip", "TS", "Jim", "nm", "hae", "Turiv", "RoM", "hiro", "Thx", "VL", "Brag"}
+	nameEnds   = []string{"arzE", "imoIV", "adao", "renco", "bacca", "ard35", "is", "_riro", "aPok", "Invadr", "Cardinals", "mey", "88", "orm", "VB", "ike", "akin", "WasOby", "SALT", "hinn"}
+)
+
+// Hand is a generated hand history and the hand the parser is expected to read from it.
+type Hand struct {
+	Text []byte
+	Want hands.Hand
+}
+
+// Generator generates hand histories belonging to a single hero at a single stake. A Generator is not safe for
+// concurrent use.
+type Generator struct {
+	rng        *rand.Rand
+	hero       string
+	names      []string // the usernames of the hero's opponents
+	small, big int      // the blinds in cents
+	handID     int64
+	date       time.Time
+}
+
+// New returns a Generator whose hands are determined by seed.
+func New(seed uint64) *Generator {
+	rng := rand.New(rand.NewPCG(seed, seed))
+
+	var names []string
+	for len(names) < players {
+		name := nameStarts[rng.IntN(len(nameStarts))] + nameEnds[rng.IntN(len(nameEnds))]
+		if rng.IntN(3) == 0 {
+			name += strconv.Itoa(rng.IntN(1000))
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	blinds := stakes[rng.IntN(len(stakes))]
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	return &Generator{
+		rng:    rng,
+		hero:   names[0],
+		names:  names[1:],
+		small:  blinds[0],
+		big:    blinds[1],
+		handID: 250_000_000_000 + rng.Int64N(10_000_000_000),
+		date:   start.Add(time.Duration(rng.Int64N(365*24*60*60)) * time.Second),
+	}
+}
+
+// Next generates the next hand. Hands follow one another in time and have increasing hand IDs, as hands in a file do.
+func (g *Generator) Next() Hand {
+	g.handID += 1 + g.rng.Int64N(1000)
+	g.date = g.date.Add(time.Duration(10+g.rng.IntN(110)) * time.Second)
+
+	gm := g.newGame()
+	gm.play()
+	return Hand{Text: gm.buf.Bytes(), Want: gm.want}
+}
+
+// File generates n hands separated by blank lines, as PokerStars writes them to a file, along with the hands the
+// parser is expected to read from it.
+func (g *Generator) File(n int) ([]byte, []hands.Hand) {
+	var buf bytes.Buffer
+	want := make([]hands.Hand, 0, n)
+
+	for i := range n {
+		if i > 0 {
+			buf.WriteString(hands.HandSeparator)
+		}
+		h := g.Next()
+		buf.Write(h.Text)
+		want = append(want, h.Want)
+	}
+	return buf.Bytes(), want
+}
+
+// FS generates a file system of hand history files, each with handsPerFile hands, along with the hands the parser
+// is expected to read from them.
+func (g *Generator) FS(files, handsPerFile int) (fstest.MapFS, []hands.Hand) {
+	fileSystem := fstest.MapFS{}
+	var want []hands.Hand
+
+	for i := range files {
+		data, fileHands := g.File(handsPerFile)
+		fileSystem[fmt.Sprintf("HH%03d.txt", i+1)] = &fstest.MapFile{Data: data}
+		want = append(want, fileHands...)
+	}
+	return fileSystem, want
+}
+
+// newGame seats the hero and some of their opponents at a random table, ready to play the next hand.
+func (g *Generator) newGame() *game {
+	size := tableSizes[g.rng.IntN(len(tableSizes))]
+	seated := size
+	if g.rng.IntN(3) == 0 {
+		seated = 2 + g.rng.IntN(size-1)
+	}
+	seats := g.rng.Perm(size)[:seated]
+	slices.Sort(seats)
+
+	names := make([]string, len(seats))
+	for i, n := range g.rng.Perm(len(g.names))[:len(seats)] {
+		names[i] = g.names[n]
+	}
+	names[g.rng.IntN(len(names))] = g.hero
+
+	gm := &game{
+		rng:   g.rng,
+		small: g.small,
+		big:   g.big,
+		deck:  newDeck(g.rng),
+	}
+
+	for i, seat := range seats {
+		stack := g.big*(20+g.rng.IntN(230)) + g.rng.IntN(g.big)
+		gm.players = append(gm.players, &player{name: names[i], seat: seat + 1, stack: stack, chips: stack})
+	}
+	gm.button = g.rng.IntN(len(gm.players))
+
+	gm.header(g.handID, g.date, tableNames[g.rng.IntN(len(tableNames))], size, g.rng.IntN(2) == 0, g.hero)
+	return gm
+}
+
+// newDeck returns a shuffled deck of cards.
+func newDeck(rng *rand.Rand) []hands.Card {
+	deck := make([]hands.Card, 0, 52)
+	for _, rank := range "23456789TJQKA" {
+		for _, suit := range "cdhs" {
+			deck = append(deck, hands.Card([]rune{rank, suit}))
+		}
+	}
+
+	rng.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+	return deck
+}
+
+// amount formats cents the way PokerStars does, e.g. $5, $0.10 or $5.20
+func amount(cents int) string {
+	if cents%100 == 0 {
+		return fmt.Sprintf("$%d", cents/100)
+	}
+	return fmt.Sprintf("$%d.%02d", cents/100, cents%100)
+}
+
+// dollars returns cents as the amount the parser reads them as
+func dollars(cents int) float64 {
+	return float64(cents) / 100
+}
@@ -0,0 +1,114 @@
+package handgen
+
+import (
+	"bytes"
+	"math"
+	"pokerhud/hands"
+	"reflect"
+	"testing"
+)
+
+func TestGeneratorDeterministic(t *testing.T) {
+	first, _ := New(7).File(50)
+	second, _ := New(7).File(50)
+	if !bytes.Equal(first, second) {
+		t.Error("wanted the same hands from the same seed")
+	}
+
+	other, _ := New(8).File(50)
+	if bytes.Equal(first, other) {
+		t.Error("wanted different hands from a different seed")
+	}
+}
+
+func TestGeneratedHandsParse(t *testing.T) {
+	for _, seed := range []uint64{1, 2, 3, 4, 5} {
+		fileSystem, want := New(seed).FS(10, 100)
+
+		got := map[string]hands.Hand{}
+		result := hands.ExportHandsWithOptions(fileSystem, hands.ExportOptions{
+			OnHand: func(h hands.Hand) error {
+				got[h.Metadata.ID] = h
+				return nil
+			},
+		})
+
+		if result.FileErrorCount() != 0 || result.HandErrCount() != 0 || len(result.UnrecognisedLines) != 0 {
+			t.Fatalf("seed %d: wanted every hand parsed cleanly but got %d file errors, %d hand errors and %v",
+				seed, result.FileErrorCount(), result.HandErrCount(), result.UnrecognisedLines)
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("seed %d: wanted %d hands but got %d", seed, len(want), len(got))
+		}
+
+		for _, w := range want {
+			if g := got[w.Metadata.ID]; !reflect.DeepEqual(g, w) {
+				t.Errorf("seed %d: wanted %#v but got %#v", seed, w, g)
+			}
+		}
+	}
+}
+
+func TestGeneratedHandsBalance(t *testing.T) {
+	g := New(11)
+	for range 2000 {
+		h := g.Next().Want
+
+		won := 0.0
+		for _, w := range h.Summary.Winners {
+			won += w.Amount
+		}
+		if cents(won) != cents(h.Summary.Pot-h.Summary.Rake) {
+			t.Fatalf("hand %s: wanted %v won from a pot of %v with %v rake", h.Metadata.ID, won, h.Summary.Pot, h.Summary.Rake)
+		}
+
+		invested := 0.0
+		stacks := map[string]float64{}
+		for _, p := range h.Players {
+			stacks[p.Username] = p.ChipCount
+		}
+		for player, amount := range h.Invested() {
+			if amount > stacks[player] {
+				t.Fatalf("hand %s: wanted %s to invest at most their stack %v but got %v", h.Metadata.ID, player, stacks[player], amount)
+			}
+			invested += amount
+		}
+		if cents(invested) != cents(h.Summary.Pot) {
+			t.Fatalf("hand %s: wanted %v invested for a pot of %v", h.Metadata.ID, invested, h.Summary.Pot)
+		}
+	}
+}
+
+func TestGeneratorCoverage(t *testing.T) {
+	features := map[string]int{}
+	g := New(3)
+	for range 2000 {
+		h := g.Next()
+		for feature, sig := range map[string]string{
+			"run twice":    "Hand was run twice",
+			"side pot":     "from side pot",
+			"showdown":     "*** SHOW DOWN ***",
+			"all-in":       "and is all-in",
+			"uncalled bet": "Uncalled bet (",
+		} {
+			if bytes.Contains(h.Text, []byte(sig)) {
+				features[feature]++
+			}
+		}
+		if len(h.Want.Summary.Winners) > 1 {
+			features["several winners"]++
+		}
+	}
+
+	for _, feature := range []string{"run twice", "side pot", "showdown", "all-in", "uncalled bet", "several winners"} {
+		if features[feature] == 0 {
+			t.Errorf("wanted some hands with a %s but got none", feature)
+		}
+	}
+}
+
+// cents rounds a dollar amount to whole cents, so sums of amounts can be compared
+func cents(dollars float64) int64 {
+	return int64(math.Round(dollars * 100))
+}
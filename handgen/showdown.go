@@ -0,0 +1,268 @@
+package handgen
+
+import (
+	"fmt"
+	"pokerhud/hands"
+	"slices"
+	"strings"
+)
+
+// pot is the main pot or a side pot, and the players who can win it.
+type pot struct {
+	amount   int
+	eligible []*player
+}
+
+// pots splits the chips put in by the players into the main pot, contested by every player who has not folded, and
+// a side pot for each player all-in for less than the others.
+func (g *game) pots() []pot {
+	var levels []int
+	for _, p := range g.players {
+		if p.folded == "" {
+			levels = append(levels, p.total)
+		}
+	}
+	slices.Sort(levels)
+
+	var pots []pot
+	prev := 0
+	for _, level := range slices.Compact(levels) {
+		var pt pot
+		for _, p := range g.players {
+			pt.amount += min(p.total, level) - min(p.total, prev)
+			if p.folded == "" && p.total >= level {
+				pt.eligible = append(pt.eligible, p)
+			}
+		}
+		pots = append(pots, pt)
+		prev = level
+	}
+	return pots
+}
+
+// potName returns how the ith of n pots is named in the lines of the players collecting it.
+func potName(i, n int) string {
+	switch {
+	case n == 1:
+		return "pot"
+	case i == 0:
+		return "main pot"
+	case n == 2:
+		return "side pot"
+	default:
+		return fmt.Sprintf("side pot-%d", i)
+	}
+}
+
+// rake returns the rake taken from a pot of the given size: 5% once a flop has been dealt.
+func (g *game) rake(pot int) int {
+	if len(g.boards[0]) == 0 {
+		return 0
+	}
+	return pot * 5 / 100
+}
+
+// foldedOut awards the pot to the only player who has not folded.
+func (g *game) foldedOut() {
+	winner := g.players[slices.IndexFunc(g.players, func(p *player) bool { return p.folded == "" })]
+	total := g.pot()
+	rake := g.rake(total)
+	winner.won[0] = total - rake
+
+	g.line("%s collected %s from pot", winner.name, amount(winner.won[0]))
+	g.line("%s: doesn't show hand ", winner.name)
+
+	// the parser counts a pot won before the flop as won on no board
+	board := 1
+	if g.street == hands.Preflop {
+		board = 0
+	}
+	g.want.Summary.Winners = []hands.Winner{{PlayerName: winner.name, Amount: dollars(winner.won[0]), Board: board}}
+
+	g.summary(total, rake, nil, false)
+}
+
+// showdown has the players who have not folded show their cards, and awards each pot on each board to one of the
+// players who can win it, or splits it between two of them.
+func (g *game) showdown() {
+	total := g.pot()
+	rake := g.rake(total)
+	pots := g.pots()
+
+	// the rake comes out of the largest pot, which is always larger than the rake
+	largest := 0
+	for i, pt := range pots {
+		if pt.amount > pots[largest].amount {
+			largest = i
+		}
+	}
+	pots[largest].amount -= rake
+
+	boards := 1
+	if len(g.boards[1]) > 0 {
+		boards = 2
+	}
+
+	for board := range boards {
+		switch {
+		case boards == 1:
+			g.line("*** SHOW DOWN ***")
+		case board == 0:
+			g.line("*** FIRST SHOW DOWN ***")
+		default:
+			g.line("*** SECOND SHOW DOWN ***")
+		}
+
+		for _, p := range g.players {
+			if p.folded == "" {
+				g.line("%s: shows [%s %s]", p.name, p.cards[0], p.cards[1])
+			}
+		}
+
+		// side pots are collected before the main pot, with the first board taking any odd chip
+		for i := len(pots) - 1; i >= 0; i-- {
+			share := pots[i].amount / boards
+			if board == 0 {
+				share += pots[i].amount % boards
+			}
+			g.award(pots[i], potName(i, len(pots)), share, board)
+		}
+	}
+
+	g.summary(total, rake, pots, true)
+}
+
+// award gives share of pt on board to one of the players who can win it, or splits it between two of them an
+// eighth of the time.
+func (g *game) award(pt pot, name string, share, board int) {
+	winners := 1
+	if len(pt.eligible) > 1 && g.rng.IntN(8) == 0 {
+		winners = 2
+	}
+
+	for i, w := range g.rng.Perm(len(pt.eligible))[:winners] {
+		p := pt.eligible[w]
+		won := share / winners
+		if i == 0 {
+			won += share % winners
+		}
+		if won == 0 {
+			continue
+		}
+
+		p.won[board] += won
+		g.line("%s collected %s from %s", p.name, amount(won), name)
+		g.want.Summary.Winners = append(g.want.Summary.Winners, hands.Winner{
+			PlayerName: p.name,
+			Amount:     dollars(won),
+			Board:      board + 1,
+		})
+	}
+}
+
+// summary writes the summary of the hand, with the pots after the rake when there are side pots, and completes the
+// hand the parser is expected to read.
+func (g *game) summary(total, rake int, pots []pot, showdown bool) {
+	g.line("*** SUMMARY ***")
+
+	potSizes := ""
+	if len(pots) > 1 {
+		potSizes = fmt.Sprintf(" Main pot %s.", amount(pots[0].amount))
+		for i, pt := range pots[1:] {
+			potSizes += fmt.Sprintf(" %s %s.", title(potName(i+1, len(pots))), amount(pt.amount))
+		}
+	}
+	g.line("Total pot %s%s | Rake %s ", amount(total), potSizes, amount(rake))
+
+	switch {
+	case len(g.boards[1]) > 0:
+		g.line("Hand was run twice")
+		g.line("FIRST Board [%s]", cards(g.boards[0]))
+		g.line("SECOND Board [%s]", cards(g.boards[1]))
+	case len(g.boards[0]) > 0:
+		g.line("Board [%s]", cards(g.boards[0]))
+	}
+
+	n := len(g.players)
+	positions := map[int]string{g.button: " (button)", (g.button + 1) % n: " (small blind)", (g.button + 2) % n: " (big blind)"}
+	if n == 2 {
+		positions = map[int]string{g.button: " (button) (small blind)", (g.button + 1) % n: " (big blind)"}
+	}
+
+	for i, p := range g.players {
+		result := ""
+		switch {
+		case p.folded == hands.Preflop && p.total == 0:
+			result = "folded before Flop (didn't bet)"
+		case p.folded == hands.Preflop:
+			result = "folded before Flop"
+		case p.folded != "":
+			result = "folded on the " + title(string(p.folded))
+		case showdown:
+			result = fmt.Sprintf("showed [%s %s] and %s", p.cards[0], p.cards[1], g.results(p))
+		default:
+			result = fmt.Sprintf("collected (%s)", amount(p.won[0]))
+		}
+		g.line("Seat %d: %s%s %s", p.seat, p.name, positions[i], result)
+	}
+
+	g.want.Players = make([]hands.Player, n)
+	for i, p := range g.players {
+		g.want.Players[i] = hands.Player{Username: p.name, Seat: p.seat, ChipCount: dollars(p.stack)}
+		if p.name == g.want.Metadata.Hero || showdown && p.folded == "" {
+			g.want.Players[i].Cards = p.cards
+		}
+	}
+
+	g.want.Summary.Pot = dollars(total)
+	g.want.Summary.Rake = dollars(rake)
+	for i, b := range g.boards {
+		g.want.Summary.CommunityCards[i] = communityCards(b)
+	}
+}
+
+// results describes what p won or lost on each board at showdown, e.g. "won ($5.03), and lost".
+func (g *game) results(p *player) string {
+	boards := 1
+	if len(g.boards[1]) > 0 {
+		boards = 2
+	}
+
+	results := make([]string, boards)
+	for board := range boards {
+		results[board] = "lost"
+		if p.won[board] > 0 {
+			results[board] = fmt.Sprintf("won (%s)", amount(p.won[board]))
+		}
+	}
+	return strings.Join(results, ", and ")
+}
+
+// title returns s with its first letter in upper case, e.g. "Side pot" or "Flop"
+func title(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// cards returns cards separated by spaces.
+func cards(cs []hands.Card) string {
+	s := make([]string, len(cs))
+	for i, c := range cs {
+		s[i] = string(c)
+	}
+	return strings.Join(s, " ")
+}
+
+// communityCards returns the cards dealt to a board as the parser reads them.
+func communityCards(board []hands.Card) hands.CommunityCards {
+	var cc hands.CommunityCards
+	if len(board) >= 3 {
+		cc.Flop = [3]hands.Card{board[0], board[1], board[2]}
+	}
+	if len(board) >= 4 {
+		cc.Turn = board[3]
+	}
+	if len(board) >= 5 {
+		cc.River = board[4]
+	}
+	return cc
+}
@@ -0,0 +1,94 @@
+package hands
+
+import "math"
+
+// StreetBets tracks the chips each player has put in on the current street, to convert between the amount a player
+// raised by and the total they raised to.
+type StreetBets struct {
+	street    Street
+	committed map[string]float64
+	highest   float64
+}
+
+// NewStreetBets returns a StreetBets starting preflop.
+func NewStreetBets() *StreetBets {
+	return &StreetBets{street: Preflop, committed: map[string]float64{}}
+}
+
+// Add records a player putting amount into the pot on street, starting a new street if required.
+func (b *StreetBets) Add(street Street, player string, amount float64) {
+	if street != b.street {
+		b.street = street
+		b.committed = map[string]float64{}
+		b.highest = 0
+	}
+
+	b.committed[player] = RoundAmount(b.committed[player] + amount)
+	b.highest = max(b.highest, b.committed[player])
+}
+
+// Raise records a player raising by amount on street and returns the total they raised to.
+func (b *StreetBets) Raise(street Street, player string, amount float64) float64 {
+	to := RoundAmount(b.Highest(street) + amount)
+	b.Add(street, player, to-b.Committed(street, player))
+	return to
+}
+
+// Committed returns the chips player has put in on street so far.
+func (b *StreetBets) Committed(street Street, player string) float64 {
+	if street != b.street {
+		return 0
+	}
+	return b.committed[player]
+}
+
+// Highest returns the most any player has put in on street so far.
+func (b *StreetBets) Highest(street Street) float64 {
+	if street != b.street {
+		return 0
+	}
+	return b.highest
+}
+
+// RoundAmount rounds amount to the nearest cent, dropping the error that adding amounts up accumulates.
+func RoundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// Invested returns the chips each player put into the pot, excluding any uncalled bet returned to them.
+func (h Hand) Invested() map[string]float64 {
+	invested := map[string]float64{}
+	bets := NewStreetBets()
+
+	for _, a := range h.Actions {
+		switch a.ActionType {
+		case ActionPost, ActionCall, ActionBet:
+			bets.Add(a.Street, a.PlayerName, a.Amount)
+			invested[a.PlayerName] += a.Amount
+		case ActionRaise:
+			before := bets.Committed(a.Street, a.PlayerName)
+			to := bets.Raise(a.Street, a.PlayerName, a.Amount)
+			invested[a.PlayerName] += to - before
+		}
+	}
+
+	// the part of the last bet no one else matched was returned to the player who made it
+	var top, second float64
+	var topPlayer string
+	for player, committed := range bets.committed {
+		switch {
+		case committed > top:
+			top, second, topPlayer = committed, top, player
+		case committed > second:
+			second = committed
+		}
+	}
+	if topPlayer != "" {
+		invested[topPlayer] -= top - second
+	}
+
+	for player, amount := range invested {
+		invested[player] = RoundAmount(amount)
+	}
+	return invested
+}
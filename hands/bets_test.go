@@ -0,0 +1,65 @@
+package hands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInvested(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want map[string]float64
+	}{
+		{
+			"uncalled bet is returned",
+			testHands,
+			map[string]float64{"kv_def": 0.12, "KavarzE": 0.12, "JDfq28": 0.12},
+		},
+		{
+			"all-in call for less returns the difference",
+			runItTwicePlayerWonBothBoards,
+			map[string]float64{"loto_insane": 0.11, "KavarzE": 6.88, "Braghinn": 0.11, "Gatzin": 6.88},
+		},
+		{
+			"walk returns the big blind's excess",
+			allFoldedBeforeFlop,
+			map[string]float64{"bk4crs": 0.02, "KavarzE": 0.05, "OoJohnStevensoO": 0.05},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := parseText(t, []byte(tt.text))[0]
+			got := h.Invested()
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wanted %v but got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStreetBets(t *testing.T) {
+	bets := NewStreetBets()
+	bets.Add(Preflop, "sb", 0.02)
+	bets.Add(Preflop, "bb", 0.05)
+
+	if to := bets.Raise(Preflop, "sb", 0.1); to != 0.15 {
+		t.Errorf("wanted a raise to 0.15 but got %v", to)
+	}
+	if got := bets.Committed(Preflop, "sb"); got != 0.15 {
+		t.Errorf("wanted 0.15 committed preflop but got %v", got)
+	}
+
+	// the first raise of a street is by the amount raised, as no one has bet yet
+	if to := bets.Raise(Flop, "bb", 0.3); to != 0.3 {
+		t.Errorf("wanted a raise to 0.3 on the flop but got %v", to)
+	}
+	if got := bets.Committed(Flop, "sb"); got != 0 {
+		t.Errorf("wanted nothing committed on the flop but got %v", got)
+	}
+	if got := bets.Highest(Turn); got != 0 {
+		t.Errorf("wanted nothing bet on the turn but got %v", got)
+	}
+}
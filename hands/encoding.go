@@ -0,0 +1,142 @@
+package hands
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// fileEncoding is the character encoding of a hand history file. Files are transcoded to UTF-8 as they are read,
+// while positions within a file are kept in the file's own bytes, so that an import can be resumed where it stopped.
+type fileEncoding int
+
+const (
+	encodingUTF8 fileEncoding = iota
+	encodingUTF16LE
+	encodingUTF16BE
+)
+
+// encodingHeadLen is the number of bytes at the start of a file that detectEncoding needs
+const encodingHeadLen = 3
+
+var (
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16LE = []byte{0xff, 0xfe}
+	bomUTF16BE = []byte{0xfe, 0xff}
+)
+
+// detectEncoding returns the encoding of a file starting with head, along with the length of its byte order mark.
+// A file without a byte order mark is UTF-16 when one of the first two bytes is zero, as a history starts with the
+// ASCII "PokerStars", and UTF-8 otherwise.
+func detectEncoding(head []byte) (enc fileEncoding, bomLen int) {
+	switch {
+	case bytes.HasPrefix(head, bomUTF8):
+		return encodingUTF8, len(bomUTF8)
+	case bytes.HasPrefix(head, bomUTF16LE):
+		return encodingUTF16LE, len(bomUTF16LE)
+	case bytes.HasPrefix(head, bomUTF16BE):
+		return encodingUTF16BE, len(bomUTF16BE)
+	case len(head) >= 2 && head[0] != 0 && head[1] == 0:
+		return encodingUTF16LE, 0
+	case len(head) >= 2 && head[0] == 0 && head[1] != 0:
+		return encodingUTF16BE, 0
+	}
+	return encodingUTF8, 0
+}
+
+// reader returns a reader of the text of r, a file in e, transcoded to UTF-8.
+func (e fileEncoding) reader(r io.Reader) io.Reader {
+	if e == encodingUTF8 {
+		return r
+	}
+	return &utf16Reader{r: r, bigEndian: e == encodingUTF16BE}
+}
+
+// size returns the number of bytes text, read from a file in e, takes up in the file.
+func (e fileEncoding) size(text []byte) int64 {
+	if e == encodingUTF8 {
+		return int64(len(text))
+	}
+
+	units := 0
+	for i := 0; i < len(text); {
+		if text[i] < utf8.RuneSelf {
+			units++
+			i++
+			continue
+		}
+		r, n := utf8.DecodeRune(text[i:])
+		units += utf16.RuneLen(r)
+		i += n
+	}
+	return 2 * int64(units)
+}
+
+// utf16Reader transcodes UTF-16 read from r to UTF-8. An invalid surrogate is read as utf8.RuneError, which takes up
+// a single code unit as the surrogate did, and an odd byte at the end of r is dropped.
+type utf16Reader struct {
+	r         io.Reader
+	bigEndian bool
+	in        [4096]byte
+	pending   int    // the bytes at the start of in not yet decoded: half a code unit or half a surrogate pair
+	out       []byte // text decoded but not yet read
+	buf       []byte
+	err       error
+}
+
+// Read implements io.Reader.
+func (d *utf16Reader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.fill()
+	}
+
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// fill reads from r and decodes the code units read, keeping back a code unit or surrogate pair cut short until the
+// rest of it is read.
+func (d *utf16Reader) fill() {
+	n, err := d.r.Read(d.in[d.pending:])
+	n += d.pending
+	atEOF := err != nil
+
+	d.buf = d.buf[:0]
+	i := 0
+	for ; i+1 < n; i += 2 {
+		r := d.unit(i)
+		if utf16.IsSurrogate(r) && r < 0xdc00 {
+			if i+3 >= n && !atEOF {
+				break // the rest of the pair is still to be read
+			}
+			if i+3 < n {
+				if pair := utf16.DecodeRune(r, d.unit(i+2)); pair != utf8.RuneError {
+					d.buf = utf8.AppendRune(d.buf, pair)
+					i += 2
+					continue
+				}
+			}
+		}
+		d.buf = utf8.AppendRune(d.buf, r) // a lone surrogate is appended as utf8.RuneError
+	}
+
+	d.pending = copy(d.in[:], d.in[i:n])
+	if atEOF {
+		d.pending = 0
+		d.err = err
+	}
+	d.out = d.buf
+}
+
+// unit returns the code unit at in[i:i+2].
+func (d *utf16Reader) unit(i int) rune {
+	if d.bigEndian {
+		return rune(d.in[i])<<8 | rune(d.in[i+1])
+	}
+	return rune(d.in[i+1])<<8 | rune(d.in[i])
+}
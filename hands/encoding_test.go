@@ -0,0 +1,102 @@
+package hands
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+)
+
+// encodeText returns text encoded in enc, starting with a byte order mark if bom is true.
+func encodeText(text string, enc fileEncoding, bom bool) []byte {
+	var data []byte
+	switch enc {
+	case encodingUTF8:
+		if bom {
+			data = append(data, bomUTF8...)
+		}
+		return append(data, text...)
+	case encodingUTF16LE:
+		if bom {
+			data = append(data, bomUTF16LE...)
+		}
+		for _, u := range utf16.Encode([]rune(text)) {
+			data = append(data, byte(u), byte(u>>8))
+		}
+	case encodingUTF16BE:
+		if bom {
+			data = append(data, bomUTF16BE...)
+		}
+		for _, u := range utf16.Encode([]rune(text)) {
+			data = append(data, byte(u>>8), byte(u))
+		}
+	}
+	return data
+}
+
+func TestDetectEncoding(t *testing.T) {
+	cases := []struct {
+		name    string
+		head    []byte
+		wantEnc fileEncoding
+		wantBOM int
+	}{
+		{"UTF-8", []byte("Pok"), encodingUTF8, 0},
+		{"UTF-8 with a byte order mark", encodeText("P", encodingUTF8, true), encodingUTF8, 3},
+		{"UTF-16LE with a byte order mark", encodeText("P", encodingUTF16LE, true), encodingUTF16LE, 2},
+		{"UTF-16LE", encodeText("Po", encodingUTF16LE, false)[:encodingHeadLen], encodingUTF16LE, 0},
+		{"UTF-16BE with a byte order mark", encodeText("P", encodingUTF16BE, true), encodingUTF16BE, 2},
+		{"UTF-16BE", encodeText("Po", encodingUTF16BE, false)[:encodingHeadLen], encodingUTF16BE, 0},
+		{"empty", nil, encodingUTF8, 0},
+		{"a single byte", []byte("P"), encodingUTF8, 0},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, bomLen := detectEncoding(tt.head)
+			if enc != tt.wantEnc || bomLen != tt.wantBOM {
+				t.Errorf("wanted encoding %d with a %d byte mark but got %d with %d", tt.wantEnc, tt.wantBOM, enc, bomLen)
+			}
+		})
+	}
+}
+
+func TestUTF16Reader(t *testing.T) {
+	cases := []struct {
+		name string
+		enc  fileEncoding
+		data []byte
+		want string
+	}{
+		{"little endian", encodingUTF16LE, encodeText("Seat 1: Jürgen ($5 in chips)\n", encodingUTF16LE, false), "Seat 1: Jürgen ($5 in chips)\n"},
+		{"big endian", encodingUTF16BE, encodeText("Seat 1: Jürgen ($5 in chips)\n", encodingUTF16BE, false), "Seat 1: Jürgen ($5 in chips)\n"},
+		{"surrogate pair", encodingUTF16LE, encodeText("Seat 2: 🂡Ace ($5 in chips)", encodingUTF16LE, false), "Seat 2: 🂡Ace ($5 in chips)"},
+		{"lone surrogate", encodingUTF16LE, []byte{'a', 0, 0x3d, 0xd8, 'b', 0}, "a�b"},
+		{"lone surrogate at the end", encodingUTF16LE, []byte{'a', 0, 0x3d, 0xd8}, "a�"},
+		{"odd byte at the end", encodingUTF16LE, []byte{'a', 0, 'b'}, "a"},
+		{"long", encodingUTF16LE, encodeText(cashGame2+"\n\n\n"+cashGame2, encodingUTF16LE, false), cashGame2 + "\n\n\n" + cashGame2},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, r := range []io.Reader{bytes.NewReader(tt.data), iotest.OneByteReader(bytes.NewReader(tt.data))} {
+				got, err := io.ReadAll(tt.enc.reader(r))
+				if err != nil || string(got) != tt.want {
+					t.Fatalf("wanted %q but got %q and %v", tt.want, got, err)
+				}
+
+				// the text takes up as many bytes as were decoded, without an odd byte at the end
+				if size := tt.enc.size(got); size != int64(len(tt.data)&^1) {
+					t.Errorf("wanted the text to take up %d bytes but got %d", len(tt.data)&^1, size)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodingSizeUTF8(t *testing.T) {
+	if size := encodingUTF8.size([]byte("Jürgen 🂡")); size != int64(len("Jürgen 🂡")) {
+		t.Errorf("wanted a UTF-8 text to take up its own length but got %d", size)
+	}
+}
@@ -5,17 +5,41 @@ import (
 	"fmt"
 	"io/fs"
 	"log"
+	"maps"
+	"slices"
 	"sync"
+	"time"
 )
 
-const maxFailRate float64 = 0.005
+// DefaultMaxFailRate is the fraction of a file's hands that may fail to parse before the file is failed
+const DefaultMaxFailRate float64 = 0.005
 
 var (
-	// ErrFailRate indicates that the number of hand errors within the file exceeds the maximum fail rate constant
+	// ErrFailRate indicates that the number of hand errors within the file exceeds the maximum fail rate
 	ErrFailRate = errors.New("error handErrs exceeded the maximum fail rate")
 
 	// ErrFileNotParsable indicates that the given file was not able to be opened or read by the scanner
 	ErrFileNotParsable = errors.New("error failed to open file for parsing")
+
+	// ErrAborted indicates that a strict import stopped at an error in another file before this file was finished
+	ErrAborted = errors.New("error import aborted")
+)
+
+// ParseMode controls how an import treats hands that fail to parse.
+type ParseMode int
+
+// Parse modes
+const (
+	// ParseDefault skips hands that fail to parse, failing a file once they exceed the maximum fail rate.
+	ParseDefault ParseMode = iota
+
+	// ParseStrict stops the import at the first hand or file that fails to parse. Every file of the import is then
+	// failed, so that none are committed to the Ledger or treated as successfully imported.
+	ParseStrict
+
+	// ParseLenient keeps hands whose only problems are action, player or winner lines that could not be parsed.
+	// Those lines are skipped and attached to the hand as Hand.Warnings.
+	ParseLenient
 )
 
 // FailRateErr returns an error containing an ErrFailRate and message
@@ -23,6 +47,11 @@ func FailRateErr(msg string) error {
 	return fmt.Errorf("%w: %s", ErrFailRate, msg)
 }
 
+// AbortedErr returns an error containing an ErrAborted and message
+func AbortedErr(msg string) error {
+	return fmt.Errorf("%w: %s", ErrAborted, msg)
+}
+
 // FileNotParsableErr returns an error containing an ErrFileNotParsable and message
 func FileNotParsableErr(msg string) error {
 	return fmt.Errorf("%w: %s", ErrFileNotParsable, msg)
@@ -32,14 +61,56 @@ func FileNotParsableErr(msg string) error {
 type ExportResult struct {
 	FileResults []FileResult
 	FsErr       error // filesystem error preventing any parsing
+	OnHandErr   error // first error returned by ExportOptions.OnHand, after which it is no longer called
+	AbortErr    error // in ParseStrict mode, the error the import stopped at
+
+	// LedgerChanged reports whether ExportOptions.Ledger recorded the progress of any file, even one that had no
+	// new hands, and so should be saved.
+	LedgerChanged bool
+
+	// UnrecognisedLines counts the lines of the imported hands that the parser did not recognise, most frequent
+	// first, showing what the parser is missing.
+	UnrecognisedLines []LineCount
 }
 
 // FileResult provides information about the file parsed, including path, number of successful hands/hand errors.
+// Hands already seen in this or a previous import are counted as Duplicates rather than HandsParsed.
 type FileResult struct {
 	Path        string
 	HandsParsed int
 	HandErrs    int
+	Duplicates  int
+	Recovered   int // hands kept by ParseLenient despite unparsable lines, included in HandsParsed
 	Err         error
+
+	Duration time.Duration // time spent reading and parsing the file
+	Bytes    int64         // bytes of hand history read, excluding any already imported per the Ledger
+}
+
+// ExportOptions configures an import run. The zero value imports every file with a fresh HandIndex.
+type ExportOptions struct {
+	// Index holds the hands already imported. Pass an index loaded from a previous run to make re-imports idempotent.
+	// If nil, a new empty index is used.
+	Index *HandIndex
+
+	// Ledger records how far each file has been parsed. When set, only hands appended since the previous import are
+	// parsed and the ledger is updated for every successful file. If nil, every file is parsed from the start.
+	Ledger *Ledger
+
+	// Quarantine, if set, is given the text and error of every hand that fails to parse.
+	Quarantine *Quarantine
+
+	// OnHand, if set, is called with every newly imported hand once its file has imported successfully, so that the
+	// hands of a file that fails are not passed on, or in ParseStrict mode once every file has. Calls are made from a
+	// single goroutine.
+	OnHand func(Hand) error
+
+	// Mode controls how hands that fail to parse are treated, see ParseMode.
+	Mode ParseMode
+
+	// MaxFailRate is the fraction of a file's hands that may fail to parse before the file is failed. If zero,
+	// DefaultMaxFailRate is used. Use ParseStrict to allow no failures at all.
+	MaxFailRate float64
 }
 
 // FileErrorCount returns the number of files in the ExportResult with a non-nil file error.
@@ -72,6 +143,25 @@ func (e *ExportResult) HandErrCount() int {
 	return count
 }
 
+// DuplicateCount returns the number of hands across all files within the ExportResult that had already been imported.
+func (e *ExportResult) DuplicateCount() int {
+	count := 0
+	for _, f := range e.FileResults {
+		count += f.Duplicates
+	}
+	return count
+}
+
+// RecoveredCount returns the number of hands across all files within the ExportResult that were kept by ParseLenient
+// despite lines that could not be parsed.
+func (e *ExportResult) RecoveredCount() int {
+	count := 0
+	for _, f := range e.FileResults {
+		count += f.Recovered
+	}
+	return count
+}
+
 // SuccessCount returns the number of files in the ExportResult that were successfully parsed with no file errors.
 func (e *ExportResult) SuccessCount() int {
 	return len(e.FileResults) - e.FileErrorCount()
@@ -89,35 +179,68 @@ func (e *ExportResult) SuccessFiles() []string {
 }
 
 type handImport struct {
-	filePath string
-	hand     Hand
-	handErr  error
-	fileErr  bool
+	filePath  string
+	hand      Hand
+	handErr   error
+	fileErr   bool
+	handText  []byte  // the text of a hand that failed to parse
+	handStart filePos // the position of handText within the file
+	partial   bool    // hand holds what could be parsed despite handErr, see ParseLenient
+	fileDone  bool    // the file has been read, or could not be read if fileErr is set, and has no more hands
 }
 
 type fileCounter struct {
-	success int
-	failure int
-	err     error
+	success   int
+	failure   int
+	duplicate int
+	recovered int
+	err       error
+	hands     []Hand     // the new hands of the file, imported once the file is known to have succeeded
+	keys      *HandIndex // the keys of hands, to count a hand repeated within the file as a duplicate
+}
+
+// checkFailRate fails the file once more of its hands have failed to parse than maxFailRate allows.
+func (c *fileCounter) checkFailRate(maxFailRate float64) {
+	if c.err == nil && failRateExceeded(c.failure, c.success+c.duplicate, maxFailRate) {
+		c.err = FailRateErr(fmt.Sprintf("%v successful, %v failed. Maximum fail rate: %v", c.success, c.failure, maxFailRate))
+	}
 }
 
 // ExportHands imports user hand history for the first time. Returns a slice of hands for insertion into the database.
 func ExportHands(fileSystem fs.FS) ExportResult {
+	return ExportHandsWithOptions(fileSystem, ExportOptions{})
+}
+
+// ExportHandsWithOptions imports user hand history as configured by opts. Hands whose HandKey is already present
+// in opts.Index are reported as duplicates, and the newly imported hands of every file imported without a file error
+// are added to the index.
+func ExportHandsWithOptions(fileSystem fs.FS, opts ExportOptions) ExportResult {
 	dir, fsErr := fs.ReadDir(fileSystem, ".")
 
 	if fsErr != nil {
 		return ExportResult{
-			nil,
-			fsErr,
+			FileResults: nil,
+			FsErr:       fsErr,
 		}
 	}
 
-	handsChannel := streamHands(fileSystem, dir)
+	metrics := newFileMetrics()
+	handsChannel := streamHands(fileSystem, dir, opts.Ledger, metrics)
+
+	result := collectResults(handsChannel, opts)
+	metrics.apply(result.FileResults)
+	if result.AbortErr != nil {
+		opts.Ledger.rollback() // files may have been abandoned before any of their hands were counted
+	} else {
+		result.LedgerChanged = opts.Ledger.commit(result.FileResults)
+	}
 
-	return collectResults(handsChannel)
+	return result
 }
 
-func streamHands(fileSystem fs.FS, dir []fs.DirEntry) <-chan handImport {
+// streamHands parses every file in dir concurrently, sending each hand to the returned channel. The bytes read and
+// time taken for each file are recorded in metrics, which may be nil.
+func streamHands(fileSystem fs.FS, dir []fs.DirEntry, ledger *Ledger, metrics *fileMetrics) <-chan handImport {
 	var wg sync.WaitGroup
 	handsChannel := make(chan handImport, 10000)
 
@@ -125,12 +248,23 @@ func streamHands(fileSystem fs.FS, dir []fs.DirEntry) <-chan handImport {
 		if !file.IsDir() {
 			wg.Go(func() {
 				fileName := file.Name()
-				ok, fsErr := extractHandsFromFile(fileSystem, fileName, handsChannel)
+				started := time.Now()
+				var read int64
+				var ok bool
+				var fsErr error
+				if ledger == nil {
+					var end filePos
+					end, ok, fsErr = extractHandsFromFileAt(fileSystem, fileName, filePos{}, false, handsChannel)
+					read = end.offset
+				} else {
+					read, ok, fsErr = extractNewHands(fileSystem, fileName, ledger, handsChannel)
+				}
+				metrics.record(fileName, read, time.Since(started))
 
 				if !ok {
-					log.Printf("An error occurred parsing file %s: %#v", fileName, fsErr.Error())
-					handsChannel <- handImport{filePath: fileName, fileErr: true}
+					log.Printf("An error occurred parsing file %s: %v", fileName, fsErr)
 				}
+				handsChannel <- handImport{filePath: fileName, fileErr: !ok, fileDone: true}
 			})
 		}
 	}
@@ -143,49 +277,174 @@ func streamHands(fileSystem fs.FS, dir []fs.DirEntry) <-chan handImport {
 	return handsChannel
 }
 
-func collectResults(handsChannel <-chan handImport) ExportResult {
+// extractNewHands parses the hands appended to fileName since it was last recorded in the ledger, staging a new
+// ledger entry once done. It returns the number of bytes parsed.
+func extractNewHands(fileSystem fs.FS, fileName string, ledger *Ledger, handsChannel chan<- handImport) (int64, bool, error) {
+	info, err := fs.Stat(fileSystem, fileName)
+	if err != nil {
+		return 0, false, err
+	}
 
-	counter := map[string]*fileCounter{}
+	start, skip, err := ledger.resumePos(fileSystem, fileName, info)
+	if err != nil {
+		return 0, false, err
+	}
+	if skip {
+		return 0, true, nil
+	}
 
-	for h := range handsChannel {
+	end, ok, fsErr := extractHandsFromFileAt(fileSystem, fileName, start, true, handsChannel)
+	if !ok {
+		return end.offset - start.offset, false, fsErr
+	}
+
+	return end.offset - start.offset, true, ledger.record(fileSystem, fileName, info, end)
+}
 
-		// // body := new(bytes.Buffer)
-		// b := strings.Builder{}
-		// jsonErr := json.NewEncoder(&b).Encode(h.hand)
+type fileMetric struct {
+	bytes    int64
+	duration time.Duration
+}
 
-		// if jsonErr != nil {
-		// 	log.Println(jsonErr.Error())
-		// }
+// fileMetrics records the bytes read and time taken to parse each file of an import.
+type fileMetrics struct {
+	mu    sync.Mutex
+	files map[string]fileMetric
+}
 
-		// log.Printf("%#v\n\n", b.String())
+func newFileMetrics() *fileMetrics {
+	return &fileMetrics{files: map[string]fileMetric{}}
+}
+
+func (m *fileMetrics) record(fileName string, bytes int64, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[fileName] = fileMetric{bytes: bytes, duration: duration}
+}
+
+// apply copies the recorded metrics onto the matching file results.
+func (m *fileMetrics) apply(results []FileResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range results {
+		fm := m.files[results[i].Path]
+		results[i].Bytes = fm.bytes
+		results[i].Duration = fm.duration
+	}
+}
+
+// collectResults counts the hands received from handsChannel per file, adding failing hands to opts.Quarantine. New
+// hands are only added to opts.Index and passed to opts.OnHand once their file has imported successfully, as the
+// Ledger only commits such files, so that the hands of a failed file are imported again rather than counted as
+// duplicates when it is re-read.
+func collectResults(handsChannel <-chan handImport, opts ExportOptions) ExportResult {
+	index := opts.Index
+	if index == nil {
+		index = NewHandIndex()
+	}
+
+	maxFailRate := opts.MaxFailRate
+	if maxFailRate == 0 {
+		maxFailRate = DefaultMaxFailRate
+	}
+
+	counter := map[string]*fileCounter{}
+	unrecognised := newLineCounter()
+	var onHandErr, abortErr error
+
+	// importFile imports the new hands of a file that has been read, unless too many of its hands failed. A hand
+	// also found in a file that imported before it is only then known to be a duplicate.
+	importFile := func(c *fileCounter) {
+		c.checkFailRate(maxFailRate)
+		if c.err == nil {
+			for _, h := range c.hands {
+				if !index.Add(h.Metadata.Key()) {
+					c.success--
+					c.duplicate++
+					continue
+				}
+				if opts.OnHand != nil && onHandErr == nil {
+					onHandErr = opts.OnHand(h)
+				}
+			}
+		}
+		c.hands = nil
+		c.keys = nil
+	}
+
+	for h := range handsChannel {
+		if abortErr != nil {
+			continue // the import has stopped, drain the remaining hands
+		}
 
 		if _, ok := counter[h.filePath]; !ok {
-			counter[h.filePath] = &fileCounter{}
+			counter[h.filePath] = &fileCounter{keys: NewHandIndex()}
 		}
+		recovered := h.partial && opts.Mode == ParseLenient
 
-		if h.fileErr {
-			counter[h.filePath].err = FileNotParsableErr("could not open file")
-		} else if h.handErr != nil {
+		if h.fileDone {
+			if h.fileErr {
+				counter[h.filePath].err = FileNotParsableErr("could not open file")
+				if opts.Mode == ParseStrict {
+					abortErr = fmt.Errorf("%s: %w", h.filePath, counter[h.filePath].err)
+				}
+			}
+			if opts.Mode != ParseStrict {
+				importFile(counter[h.filePath])
+			}
+		} else if h.handErr != nil && !recovered {
 			counter[h.filePath].failure++
-
-			handID := h.hand.Metadata.ID
-			if handID == "" {
-				handID = "no hand id"
+			log.Printf("error parsing hand %v", h.handErr)
+			opts.Quarantine.add(h)
+			if opts.Mode == ParseStrict {
+				abortErr = h.handErr
+				counter[h.filePath].err = h.handErr
 			}
-			log.Printf("got an error parsing hand %v in %v: %v", handID, h.filePath, h.handErr.Error())
+		} else if key := h.hand.Metadata.Key(); index.Contains(key) || !counter[h.filePath].keys.Add(key) {
+			counter[h.filePath].duplicate++
 		} else {
+			counter[h.filePath].hands = append(counter[h.filePath].hands, h.hand)
 			counter[h.filePath].success++
+			unrecognised.add(h.hand)
+			if recovered {
+				counter[h.filePath].recovered++
+				for _, w := range h.hand.Warnings {
+					if w.Kind != KindUnrecognised {
+						log.Printf("skipped line of hand %v", w)
+					}
+				}
+			}
+		}
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(counter)) {
+		switch c := counter[path]; {
+		case abortErr != nil:
+			if c.err == nil {
+				c.err = AbortedErr(abortErr.Error())
+			}
+		case c.hands != nil:
+			importFile(c) // a strict import, now that every file has been read without an error
 		}
 	}
-	fileResults := extractFileResults(counter)
+
+	fileResults := extractFileResults(counter, maxFailRate)
 
 	return ExportResult{
-		fileResults,
-		nil,
+		FileResults: fileResults,
+		FsErr:       nil,
+		OnHandErr:   onHandErr,
+		AbortErr:    abortErr,
+
+		UnrecognisedLines: unrecognised.lines(),
 	}
 }
 
-func extractFileResults(results map[string]*fileCounter) []FileResult {
+func extractFileResults(results map[string]*fileCounter, maxFailRate float64) []FileResult {
 	fileResults := make([]FileResult, len(results))
 	i := 0
 	for k, v := range results {
@@ -194,19 +453,18 @@ func extractFileResults(results map[string]*fileCounter) []FileResult {
 			Path:        k,
 			HandsParsed: v.success,
 			HandErrs:    v.failure,
+			Duplicates:  v.duplicate,
+			Recovered:   v.recovered,
 		}
-		if v.err != nil {
-			fr.Err = v.err
-		} else if failRateExceeded(v.failure, v.success) {
-			fr.Err = FailRateErr(fmt.Sprintf("%v successful, %v failed. Maximum fail rate: %v", v.success, v.failure, maxFailRate))
-		}
+		v.checkFailRate(maxFailRate)
+		fr.Err = v.err
 		fileResults[i] = fr
 		i++
 	}
 	return fileResults
 }
 
-func failRateExceeded(failure, success int) bool {
+func failRateExceeded(failure, success int, maxFailRate float64) bool {
 	total := float64(success) + float64(failure)
 	if total == 0 {
 		return false
@@ -6,6 +6,7 @@ import (
 	"io/fs"
 	"reflect"
 	"slices"
+	"strings"
 	"testing"
 	"testing/fstest"
 )
@@ -17,15 +18,22 @@ func TestStreamHands(t *testing.T) {
 
 	dir, _ := fs.ReadDir(fileSystem, ".")
 
-	hands := streamHands(fileSystem, dir)
+	hands := streamHands(fileSystem, dir, nil, nil)
 
-	count := 0
-	for range hands {
+	count, done := 0, 0
+	for h := range hands {
+		if h.fileDone {
+			done++
+			continue
+		}
+		if done > 0 {
+			t.Error("wanted the hand before the end of its file")
+		}
 		count++
 	}
 
-	if count != 1 {
-		t.Errorf("wanted 1 hand but got %d", count)
+	if count != 1 || done != 1 {
+		t.Errorf("wanted 1 hand and the end of 1 file but got %d and %d", count, done)
 	}
 }
 
@@ -37,9 +45,9 @@ func TestCollectResults(t *testing.T) {
 		}
 		dir, _ := fs.ReadDir(fileSystem, ".")
 
-		handsChannel := streamHands(fileSystem, dir)
+		handsChannel := streamHands(fileSystem, dir, nil, nil)
 
-		got := collectResults(handsChannel)
+		got := collectResults(handsChannel, ExportOptions{})
 
 		successCount, failureCount := sumHandsHelper(got.FileResults)
 
@@ -79,9 +87,9 @@ func TestCollectResults(t *testing.T) {
 
 		dir, _ := fs.ReadDir(fileSystem, ".")
 
-		handsChannel := streamHands(fileSystem, dir)
+		handsChannel := streamHands(fileSystem, dir, nil, nil)
 
-		got := collectResults(handsChannel)
+		got := collectResults(handsChannel, ExportOptions{})
 
 		for _, f := range got.FileResults {
 			if !errors.Is(f.Err, ErrFileNotParsable) && f.Path == "failure.txt" {
@@ -95,6 +103,133 @@ func TestCollectResults(t *testing.T) {
 	})
 }
 
+func TestExportHandsDuplicates(t *testing.T) {
+
+	t.Run("same hand in two files is counted once", func(t *testing.T) {
+		fileSystem := fstest.MapFS{
+			"zoom.txt":      {Data: []byte(testHands)},
+			"zoom-copy.txt": {Data: []byte(testHands)},
+		}
+
+		result := ExportHands(fileSystem)
+
+		if result.HandsCount() != 1 {
+			t.Errorf("wanted 1 hand parsed but got %d", result.HandsCount())
+		}
+
+		if result.DuplicateCount() != 1 {
+			t.Errorf("wanted 1 duplicate but got %d", result.DuplicateCount())
+		}
+
+		for _, f := range result.FileResults {
+			if f.Err != nil {
+				t.Errorf("wanted no file errors but got %v for %s", f.Err, f.Path)
+			}
+		}
+	})
+
+	t.Run("re-import with the same index is idempotent", func(t *testing.T) {
+		fileSystem := fstest.MapFS{
+			"zoom.txt": {Data: []byte(testHands)},
+			"rit.txt":  {Data: []byte(runItTwice)},
+		}
+		index := NewHandIndex()
+
+		first := ExportHandsWithOptions(fileSystem, ExportOptions{Index: index})
+		second := ExportHandsWithOptions(fileSystem, ExportOptions{Index: index})
+
+		if first.HandsCount() != 2 || first.DuplicateCount() != 0 {
+			t.Errorf("first import: wanted 2 hands and 0 duplicates but got %d and %d", first.HandsCount(), first.DuplicateCount())
+		}
+
+		if second.HandsCount() != 0 || second.DuplicateCount() != 2 {
+			t.Errorf("second import: wanted 0 hands and 2 duplicates but got %d and %d", second.HandsCount(), second.DuplicateCount())
+		}
+
+		if index.Len() != 2 {
+			t.Errorf("wanted 2 hands in the index but got %d", index.Len())
+		}
+	})
+
+	t.Run("hands of a failed file are not indexed", func(t *testing.T) {
+		noHandID := strings.Replace(cashGame2, "Hand #254446123323", "Hand", 1)
+		fileSystem := fstest.MapFS{
+			"zoom.txt": {Data: []byte(testHands + "\n\n\n" + noHandID + "\n\n\n")},
+		}
+		index := NewHandIndex()
+		ledger := NewLedger()
+
+		first := ExportHandsWithOptions(fileSystem, ExportOptions{Index: index, Ledger: ledger})
+		if first.FileErrorCount() != 1 || index.Len() != 0 {
+			t.Fatalf("wanted the file to fail with no hands indexed but got %d file errors and %d hands", first.FileErrorCount(), index.Len())
+		}
+
+		// the fixed file is read again from the start, as its ledger entry was not committed
+		fileSystem["zoom.txt"].Data = []byte(testHands + "\n\n\n" + cashGame2 + "\n\n\n")
+		second := ExportHandsWithOptions(fileSystem, ExportOptions{Index: index, Ledger: ledger})
+		if second.HandsCount() != 2 || second.DuplicateCount() != 0 || index.Len() != 2 {
+			t.Errorf("wanted 2 new hands indexed but got %d hands, %d duplicates and %d indexed",
+				second.HandsCount(), second.DuplicateCount(), index.Len())
+		}
+	})
+
+	t.Run("a hand shared with a failed file is imported from the file that succeeds", func(t *testing.T) {
+		noHandID := strings.Replace(cashGame2, "Hand #254446123323", "Hand", 1)
+		fileSystem := fstest.MapFS{
+			"a.txt": {Data: []byte(testHands + "\n\n\n" + noHandID + "\n\n\n" + noHandID + "\n\n\n")},
+			"b.txt": {Data: []byte(testHands + "\n\n\n")},
+		}
+		index := NewHandIndex()
+
+		var got []string
+		result := ExportHandsWithOptions(fileSystem, ExportOptions{
+			Index: index,
+			OnHand: func(h Hand) error {
+				got = append(got, h.Metadata.ID)
+				return nil
+			},
+		})
+
+		byPath := map[string]FileResult{}
+		for _, fr := range result.FileResults {
+			byPath[fr.Path] = fr
+		}
+		if a := byPath["a.txt"]; a.Err == nil {
+			t.Errorf("wanted a.txt to fail but got %+v", a)
+		}
+		if b := byPath["b.txt"]; b.Err != nil || b.HandsParsed != 1 || b.Duplicates != 0 {
+			t.Errorf("wanted b.txt to import its hand but got %+v", b)
+		}
+
+		if !reflect.DeepEqual(got, []string{"254489598204"}) || index.Len() != 1 {
+			t.Errorf("wanted hand 254489598204 imported and indexed but got %v and %d indexed", got, index.Len())
+		}
+	})
+
+	t.Run("OnHand is only called for new hands", func(t *testing.T) {
+		fileSystem := fstest.MapFS{
+			"zoom.txt":      {Data: []byte(testHands)},
+			"zoom-copy.txt": {Data: []byte(testHands)},
+		}
+
+		var got []string
+		result := ExportHandsWithOptions(fileSystem, ExportOptions{
+			OnHand: func(h Hand) error {
+				got = append(got, h.Metadata.ID)
+				return nil
+			},
+		})
+
+		if !reflect.DeepEqual(got, []string{"254489598204"}) {
+			t.Errorf("wanted OnHand called once with hand 254489598204 but got %v", got)
+		}
+
+		if result.OnHandErr != nil {
+			t.Errorf("wanted nil OnHandErr but got %v", result.OnHandErr)
+		}
+	})
+}
+
 func TestExtractFileResults(t *testing.T) {
 
 	t.Run("parsable files one successful path one unsuccessful path", func(t *testing.T) {
@@ -113,9 +248,9 @@ func TestExtractFileResults(t *testing.T) {
 			},
 		}
 
-		got := extractFileResults(data)
+		got := extractFileResults(data, DefaultMaxFailRate)
 		want := []FileResult{
-			{"zoom.txt", 121, 1, nil}, {failureFileName, 0, 5, ErrFailRate},
+			{Path: "zoom.txt", HandsParsed: 121, HandErrs: 1}, {Path: failureFileName, HandErrs: 5, Err: ErrFailRate},
 		}
 
 		if len(got) != 2 {
@@ -149,7 +284,7 @@ func TestExtractFileResults(t *testing.T) {
 			},
 		}
 
-		fileResults := extractFileResults(data)
+		fileResults := extractFileResults(data, DefaultMaxFailRate)
 
 		for _, f := range fileResults {
 			if f.Err == nil && f.Path == failureFileName {
@@ -168,7 +303,7 @@ func TestCheckFailureRate(t *testing.T) {
 
 	success := 101
 	failure := 1
-	ok := failRateExceeded(failure, success)
+	ok := failRateExceeded(failure, success, DefaultMaxFailRate)
 
 	if !ok {
 		t.Fatal("wanted true checkFailureRate but was false")
@@ -198,6 +333,128 @@ func TestSuccessFiles(t *testing.T) {
 	}
 }
 
+func TestExportHandsFileMetrics(t *testing.T) {
+	fileSystem := fstest.MapFS{
+		"zoom.txt": {Data: []byte(testHands)},
+		"rit.txt":  {Data: []byte(runItTwice)},
+	}
+
+	result := ExportHands(fileSystem)
+
+	for _, f := range result.FileResults {
+		if want := int64(len(fileSystem[f.Path].Data)); f.Bytes != want {
+			t.Errorf("wanted %d bytes read from %s but got %d", want, f.Path, f.Bytes)
+		}
+
+		if f.Duration <= 0 {
+			t.Errorf("wanted a duration recorded for %s but got %v", f.Path, f.Duration)
+		}
+	}
+}
+
+func TestExportHandsParseModes(t *testing.T) {
+	fileSystem := fstest.MapFS{
+		"broken.txt": {Data: []byte(brokenHands + "\n\n\n")}, // hand 254671591484 has an unparsable raise
+		"zoom.txt":   {Data: []byte(testHands + "\n\n\n")},
+	}
+
+	tests := []struct {
+		name          string
+		opts          ExportOptions
+		wantErrs      map[string]error // wanted FileResult.Err by path, nil if the file should succeed
+		wantRecovered int
+		wantLedger    []string
+	}{
+		{
+			name:       "default skips the hand and fails the file",
+			opts:       ExportOptions{},
+			wantErrs:   map[string]error{"broken.txt": ErrFailRate, "zoom.txt": nil},
+			wantLedger: []string{"zoom.txt"},
+		},
+		{
+			name:       "a higher fail rate keeps the file",
+			opts:       ExportOptions{MaxFailRate: 0.5},
+			wantErrs:   map[string]error{"broken.txt": nil, "zoom.txt": nil},
+			wantLedger: []string{"broken.txt", "zoom.txt"},
+		},
+		{
+			name:          "lenient keeps the hand with a warning",
+			opts:          ExportOptions{Mode: ParseLenient},
+			wantErrs:      map[string]error{"broken.txt": nil, "zoom.txt": nil},
+			wantRecovered: 1,
+			wantLedger:    []string{"broken.txt", "zoom.txt"},
+		},
+		{
+			name:     "strict aborts every file",
+			opts:     ExportOptions{Mode: ParseStrict},
+			wantErrs: map[string]error{"broken.txt": ErrFailToParseAction, "zoom.txt": ErrAborted},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warnings []*ParseError
+			handed := 0
+			tt.opts.Ledger = NewLedger()
+			tt.opts.OnHand = func(h Hand) error {
+				handed++
+				for _, w := range h.Warnings {
+					if w.Kind != KindUnrecognised {
+						warnings = append(warnings, w)
+					}
+				}
+				return nil
+			}
+			result := ExportHandsWithOptions(fileSystem, tt.opts)
+
+			for _, f := range result.FileResults {
+				want := tt.wantErrs[f.Path]
+				if !errors.Is(f.Err, want) {
+					t.Errorf("wanted %s to fail with %v but got %v", f.Path, want, f.Err)
+				}
+			}
+
+			// only the hands of the files that succeed are passed on
+			wantHanded := 0
+			for _, f := range result.FileResults {
+				if f.Err == nil {
+					wantHanded += f.HandsParsed
+				}
+			}
+			if handed != wantHanded || result.HandsCount() == 0 {
+				t.Errorf("wanted the %d hands of the files that succeeded passed to OnHand but got %d", wantHanded, handed)
+			}
+
+			if (tt.opts.Mode == ParseStrict) != (result.AbortErr != nil) {
+				t.Errorf("wanted an abort error only in strict mode but got %v", result.AbortErr)
+			}
+
+			recovered := 0
+			for _, f := range result.FileResults {
+				recovered += f.Recovered
+			}
+			if recovered != tt.wantRecovered || len(warnings) != tt.wantRecovered {
+				t.Errorf("wanted %d recovered hands but got %d with %d warnings", tt.wantRecovered, recovered, len(warnings))
+			}
+			for _, w := range warnings {
+				if w.HandID != "254671591484" || w.Kind != KindAction || w.File != "broken.txt" {
+					t.Errorf("wanted an action warning for hand 254671591484 but got %v", w)
+				}
+			}
+
+			var ledgered []string
+			for _, path := range []string{"broken.txt", "zoom.txt"} {
+				if _, ok := tt.opts.Ledger.Entry(path); ok {
+					ledgered = append(ledgered, path)
+				}
+			}
+			if !slices.Equal(ledgered, tt.wantLedger) {
+				t.Errorf("wanted ledger entries for %v but got %v", tt.wantLedger, ledgered)
+			}
+		})
+	}
+}
+
 func sumHandsHelper(exportResult []FileResult) (successCount, failureCount int) {
 	successCount = 0
 	failureCount = 0
@@ -0,0 +1,90 @@
+package hands
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// HandIndex is a set of HandKeys for every hand that has already been imported. It is used to detect duplicate
+// hands within a single import and, when persisted between runs, to make re-importing the same files idempotent.
+type HandIndex struct {
+	keys map[HandKey]struct{}
+}
+
+// NewHandIndex returns an empty HandIndex ready for use.
+func NewHandIndex() *HandIndex {
+	return &HandIndex{keys: map[HandKey]struct{}{}}
+}
+
+// Contains reports whether the key has already been recorded in the index.
+func (idx *HandIndex) Contains(key HandKey) bool {
+	_, ok := idx.keys[key]
+	return ok
+}
+
+// Add records key in the index. It returns false if the key was already present.
+func (idx *HandIndex) Add(key HandKey) bool {
+	if idx.Contains(key) {
+		return false
+	}
+	idx.keys[key] = struct{}{}
+	return true
+}
+
+// Len returns the number of hands recorded in the index.
+func (idx *HandIndex) Len() int {
+	return len(idx.keys)
+}
+
+// ReadHandIndex reads an index previously written by HandIndex.WriteTo. Each line holds a site and hand ID
+// separated by a tab.
+func ReadHandIndex(r io.Reader) (*HandIndex, error) {
+	idx := NewHandIndex()
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		site, id, found := bytes.Cut(line, []byte("\t"))
+		if !found || len(id) == 0 {
+			return nil, fmt.Errorf("invalid hand index line %q", line)
+		}
+		idx.Add(HandKey{Site: string(site), ID: string(id)})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+// WriteTo writes the index to w, one key per line, sorted so the output is stable between runs.
+func (idx *HandIndex) WriteTo(w io.Writer) (int64, error) {
+	keys := make([]HandKey, 0, len(idx.keys))
+	for k := range idx.keys {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b HandKey) int {
+		return cmp.Or(cmp.Compare(a.Site, b.Site), cmp.Compare(a.ID, b.ID))
+	})
+
+	bw := bufio.NewWriter(w)
+	var written int64
+	for _, k := range keys {
+		n, err := fmt.Fprintf(bw, "%s\t%s\n", k.Site, k.ID)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, bw.Flush()
+}
@@ -0,0 +1,55 @@
+package hands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHandIndex(t *testing.T) {
+
+	t.Run("add reports whether the key is new", func(t *testing.T) {
+		idx := NewHandIndex()
+		key := HandKey{SitePokerStars, "254489598204"}
+
+		if !idx.Add(key) {
+			t.Error("wanted true adding a new key but got false")
+		}
+
+		if idx.Add(key) {
+			t.Error("wanted false adding an existing key but got true")
+		}
+
+		if !idx.Contains(key) {
+			t.Errorf("wanted index to contain %v", key)
+		}
+	})
+
+	t.Run("written index can be read back", func(t *testing.T) {
+		idx := NewHandIndex()
+		idx.Add(HandKey{SitePokerStars, "254489598204"})
+		idx.Add(HandKey{SitePokerStars, "254446123323"})
+
+		var buf bytes.Buffer
+		if _, err := idx.WriteTo(&buf); err != nil {
+			t.Fatalf("unexpected error writing index: %v", err)
+		}
+
+		got, err := ReadHandIndex(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error reading index: %v", err)
+		}
+
+		if got.Len() != 2 || !got.Contains(HandKey{SitePokerStars, "254446123323"}) {
+			t.Errorf("wanted the 2 written keys but got %#v", got.keys)
+		}
+	})
+
+	t.Run("corrupt line returns an error", func(t *testing.T) {
+		_, err := ReadHandIndex(strings.NewReader("PokerStars 254489598204\n"))
+
+		if err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+}
@@ -6,8 +6,9 @@ import (
 	"cmp"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
-	"log"
+	"runtime/debug"
 	"slices"
 	"strconv"
 	"time"
@@ -18,7 +19,12 @@ var (
 	handDelimiter = []byte("\nPokerStars ")
 	newLine       = []byte("\n")
 
+	tableSignifier  = []byte("Table '")
+	buttonSignifier = []byte(" is the button")
+	seatPrefix      = []byte("Seat ")
+
 	// Streets
+	markerPrefix     = []byte("*** ") // starts the line of every street and section marker
 	flopSignifier    = []byte("*** FLOP ***")
 	turnSignifier    = []byte("*** TURN ***")
 	riverSignifier   = []byte("*** RIVER ***")
@@ -26,20 +32,27 @@ var (
 
 	// Actions
 	heroHandPrefix            = []byte("Dealt to")
+	uncalledBetPrefix         = []byte("Uncalled bet (")
 	showedSignifier           = []byte("showed [")
 	muckedSignifier           = []byte("mucked [")
 	foldedSignifier           = []byte("folded")
 	collectedSummarySignifier = []byte("collected (")
+	wonSignifier              = []byte(" won (") // a summary seat result, winners are parsed from their collected lines
 	boardSignifier            = []byte("Board [")
 
 	showDownSignfier        = []byte("*** SHOW DOWN ***")
 	firstShowDownSignifier  = []byte("*** FIRST SHOW DOWN ***")
 	secondShowDownSignifier = []byte("*** SECOND SHOW DOWN ***")
 
+	runTwiceSignifier       = []byte("Hand was run twice")
 	ritFirstBoardSignifier  = []byte("FIRST Board [")
 	ritSecondBoardSignifier = []byte("SECOND Board [")
 	potSizeSignifier        = []byte("Total pot ")
 
+	// The ends of the lines of players collecting a pot at showdown, which is named when there are side pots
+	collectedSignifier     = []byte(" collected ")
+	collectedPotSignifiers = [][]byte{[]byte(" from pot"), []byte(" from main pot"), []byte(" from side pot")}
+
 	// Action signifiers
 	sigFolds  = []byte(" folds")
 	sigChecks = []byte(" checks")
@@ -47,222 +60,658 @@ var (
 	sigBets   = []byte(" bets")
 	sigRaises = []byte(" raises")
 	sigPosts  = []byte(" posts")
-)
 
-type ShowdownState int
-
-const (
-	noShowdown ShowdownState = iota
-	rio
-	ritFirstBoard
-	ritSecondBoard
+	// The verbs following the player's name on action lines, and on the lines of players showing or mucking their
+	// cards, which the parser has no use for
+	nameSeparator = []byte(": ")
+	actionVerbs   = []struct {
+		verb       []byte
+		actionType ActionType
+	}{
+		{[]byte("folds"), ActionFold},
+		{[]byte("checks"), ActionCheck},
+		{[]byte("calls"), ActionCall},
+		{[]byte("bets"), ActionBet},
+		{[]byte("raises"), ActionRaise},
+		{[]byte("posts"), ActionPost},
+	}
+	shownHandVerbs = [][]byte{[]byte("shows ["), []byte("doesn't show hand"), []byte("mucks hand")}
 )
 
 var siteLocation, _ = time.LoadLocation("America/New_York")
 
-func extractHandsFromFile(filesystem fs.FS, filename string, handChan chan<- handImport) (ok bool, fsErr error) {
+// maxHandSize is the size of the largest hand that is parsed. A hand is a few kilobytes, so a larger one is almost
+// always several hands run together by a corrupted delimiter, and fails as too large rather than being parsed.
+const maxHandSize = 1 << 20
+
+// deck maps the text of each card to a Card, so that the cards parsed from hands share their strings
+var deck = func() map[string]Card {
+	cards := make(map[string]Card, 52)
+	for _, rank := range "23456789TJQKA" {
+		for _, suit := range "cdhs" {
+			c := string(rank) + string(suit)
+			cards[c] = Card(c)
+		}
+	}
+	return cards
+}()
+
+// pow10 holds the powers of ten up to the most digits parseAmount divides by without rounding
+var pow10 = [...]float64{1, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10, 1e11, 1e12, 1e13, 1e14, 1e15}
+
+// extractHandsFromFileAt parses the hands in filename starting at position start, returning the position just past
+// the last hand that was parsed. When holdIncomplete is true the file may still be being written, and a trailing hand
+// is left unparsed until it is followed by a blank line, see handWritten, so that it can be picked up by a later
+// import. Otherwise the file is final and its trailing hand is parsed as it is. The file is mapped into memory
+// where possible, and read as a stream otherwise. A file in UTF-16, or starting with a byte order mark, is transcoded
+// to UTF-8 as it is read, see detectEncoding.
+func extractHandsFromFileAt(filesystem fs.FS, filename string, start filePos, holdIncomplete bool, handChan chan<- handImport) (end filePos, ok bool, fsErr error) {
 	file, err := filesystem.Open(filename)
 
 	if err != nil {
-		return false, err
+		return start, false, err
 	}
 
 	defer func() {
 		closeErr := file.Close()
 
-		if err == nil {
-			err = closeErr
+		if fsErr == nil {
+			fsErr = closeErr
 		}
 
 	}()
 
-	scanner := bufio.NewScanner(file)
+	if data, unmap, mapped := mapFile(file); mapped {
+		defer func() {
+			if unmapErr := unmap(); fsErr == nil {
+				fsErr = unmapErr
+			}
+		}()
+		return scanMappedHands(filename, data, start, holdIncomplete, handChan)
+	}
+
+	var head [encodingHeadLen]byte
+	n, err := io.ReadFull(file, head[:])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return start, false, err
+	}
+	enc, bomLen := detectEncoding(head[:n])
+	start.offset = max(start.offset, int64(bomLen))
+
+	// the file is read again from its start, with the head that has already been read in front of it where the file
+	// can't be seeked
+	var reader io.Reader = file
+	if _, ok := file.(io.Seeker); !ok {
+		reader = io.MultiReader(bytes.NewReader(head[:n]), file)
+	}
+	if err := skipTo(reader, start.offset); err != nil {
+		return start, false, err
+	}
 
-	result, scanErr := parseHands(filename, scanner, handChan)
+	scanner := bufio.NewScanner(enc.reader(reader))
+	scanner.Buffer(nil, maxHandSize)
+
+	end, result, scanErr := scanHands(filename, scanner, enc, start, holdIncomplete, handChan)
 
 	if !result {
-		return false, scanErr
+		return end, false, scanErr
 	}
 
-	return true, nil
+	return end, true, nil
 }
 
-func parseHands(filename string, fileData *bufio.Scanner, handChan chan<- handImport) (ok bool, scanErr error) {
-	fileData.Split(splitByHands())
+// skipTo advances reader, positioned at the start of a file, to offset, seeking where the reader supports it.
+func skipTo(reader io.Reader, offset int64) error {
+	if seeker, ok := reader.(io.Seeker); ok {
+		_, err := seeker.Seek(offset, io.SeekStart)
+		return err
+	}
+	_, err := io.CopyN(io.Discard, reader, offset)
+	return err
+}
 
+// scanMappedHands parses the hands in data, the contents of filename mapped into memory, starting at position start.
+// A fault reading data, as when the file is truncated while it is mapped, fails the file rather than crashing.
+func scanMappedHands(filename string, data []byte, start filePos, holdIncomplete bool, handChan chan<- handImport) (end filePos, ok bool, scanErr error) {
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+	defer func() {
+		if r := recover(); r != nil {
+			if _, fault := r.(interface{ Addr() uintptr }); !fault {
+				panic(r)
+			}
+			end, ok, scanErr = start, false, fmt.Errorf("Invalid input: %v", r)
+		}
+	}()
+
+	enc, bomLen := detectEncoding(data[:min(encodingHeadLen, len(data))])
+	start.offset = max(start.offset, int64(bomLen))
+	data = data[min(start.offset, int64(len(data))):]
+
+	if enc != encodingUTF8 {
+		scanner := bufio.NewScanner(enc.reader(bytes.NewReader(data)))
+		scanner.Buffer(nil, maxHandSize)
+		return scanHands(filename, scanner, enc, start, holdIncomplete, handChan)
+	}
+	return scanHands(filename, &bytesScanner{data: data}, enc, start, holdIncomplete, handChan)
+}
+
+// scanHands parses every hand in fileData, sending the results to handChan. start is the file position fileData
+// begins at, and end is the position just past the last hand that was parsed. A hand larger than maxHandSize fails
+// on its own, and the hands after it are parsed as usual.
+func scanHands(filename string, fileData handScanner, enc fileEncoding, start filePos, holdIncomplete bool, handChan chan<- handImport) (end filePos, ok bool, scanErr error) {
+	splitter := &handSplitter{split: splitByHands(), max: maxHandSize, enc: enc, consumed: start}
+	fileData.Split(splitter.Split)
+	end = start
+
+	parser := handParser{enc: enc}
 	for fileData.Scan() {
 		handBytes := fileData.Bytes()
 
-		metadata, metadataErr := parseMetaData(handBytes)
+		if splitter.trailing && holdIncomplete && !splitter.tooLarge && !handWritten(handBytes) {
+			return end, true, nil // the hand is still being written - leave it for the next import
+		}
+		end = splitter.consumed
 
-		if metadataErr != nil {
-			handChan <- handImport{filename, Hand{}, metadataErr, false}
-			continue // the hand lacks crucial metadata - skip
+		if len(bytes.TrimSpace(handBytes)) == 0 {
+			continue // blank lines between hands
 		}
 
-		players, actions, winners, scanHandErr := scanHandLines(handBytes)
-		if scanHandErr != nil {
-			handChan <- handImport{
-				filePath: filename,
-				hand:     Hand{},
-				handErr:  scanHandErr,
-				fileErr:  false,
-			}
-			continue // the hand lacks crucial gameplay info - skip
+		if splitter.tooLarge {
+			handChan <- tooLargeHand(filename, handBytes, enc, splitter.tokenPos)
+			continue
 		}
-		summaryStartIndex := bytes.Index(handBytes, summarySignifier)
 
-		if summaryStartIndex == -1 {
-			log.Printf("missing summary in file %v\n", filename)
+		handChan <- parser.parseHand(filename, handBytes, splitter.tokenPos)
+	}
 
-			handChan <- handImport{
-				filePath: filename,
-				hand:     Hand{},
-				handErr:  errors.New("no summary found"),
-				fileErr:  false,
-			}
-			continue // the hand lacks important summary data
-		}
+	if err := fileData.Err(); err != nil {
+		return end, false, fmt.Errorf("Invalid input: %s", err)
+	}
 
-		summary, parseSummaryErr := parseHandSummary(handBytes[summaryStartIndex:])
+	// the rest of a hand too large to parse is skipped after its token, so the file ends where the splitter stopped
+	return splitter.consumed, true, nil
+}
 
-		if parseSummaryErr != nil {
-			handChan <- handImport{
-				filePath: filename,
-				hand:     Hand{},
-				handErr:  parseSummaryErr,
-				fileErr:  false,
-			}
-			continue // the hand lacks important summary data
-		}
+// handWritten reports whether a hand at the end of a file has been written in full. PokerStars writes a hand in
+// several flushes, so a hand can end with a summary cut short, but follows every hand with blank lines, which are only
+// written once the hand is complete.
+func handWritten(handBytes []byte) bool {
+	text := bytes.TrimRight(handBytes, " \t\r\n")
+	return bytes.Count(handBytes[len(text):], newLine) >= 2
+}
+
+// section is the part of a hand a handParser has reached. The sections of a hand always come in this order, though
+// a hand won without a showdown has none.
+type section int
+
+const (
+	sectionHeader   section = iota // the first line, with the hand ID and date
+	sectionTable                   // the line naming the table and the button seat
+	sectionSeats                   // the players' seats and stacks
+	sectionStreets                 // the blinds, the hole cards and the betting on each street
+	sectionShowdown                // the players showing their cards and collecting the pots
+	sectionSummary                 // the pot, the boards and each seat's result
+)
+
+// handParser parses hands in a single pass over their lines. It is a state machine moving through the sections of a
+// hand, classifying each line once by the section it is in and how it starts, and parsing only what that kind of line
+// holds. A handParser is reused for the hands of a file: the slices it collects into are kept between hands, and the
+// names it has seen are allocated once, so that a hand's lines are parsed without allocating.
+type handParser struct {
+	section section
+	street  Street
+	order   int
+	board   int // the board the pots collected at showdown are won on, 0 before a showdown
+
+	metadata Metadata
+	summary  Summary // without the winners, which are collected separately
+	players  []Player
+	actions  []Action
+	winners  []Winner
+	warnings []*ParseError
+	potErr   *ParseError
+
+	// the line being parsed, and the header that metadata errors without a line of their own are reported at
+	lineIdx      int
+	offset       int64
+	header       []byte
+	headerIdx    int
+	headerOffset int64
+
+	names map[string]string // the names seen in the file, see intern
+	enc   fileEncoding      // the encoding of the file, which positions in the file are measured in
+}
+
+// reset readies the parser for the next hand, keeping the names it has seen and the memory of its slices.
+func (p *handParser) reset() {
+	*p = handParser{
+		street:   Preflop,
+		players:  p.players[:0],
+		actions:  p.actions[:0],
+		winners:  p.winners[:0],
+		warnings: p.warnings[:0],
+		names:    p.names,
+		enc:      p.enc,
+	}
+}
+
+// parseHand parses the text of a single hand found at pos in filename, returning a handImport with a non-nil
+// handErr, a *ParseError, if crucial data is missing.
+func (p *handParser) parseHand(filename string, handBytes []byte, pos filePos) handImport {
+	p.reset()
+
+	if metadataErr := p.scanHandLines(handBytes); metadataErr != nil {
+		return failedHand(filename, handBytes, p.enc, pos, locateErr(metadataErr, filename, "", handBytes, p.enc, pos)) // the hand lacks crucial metadata - skip
+	}
+
+	metadata := p.metadata
+	fail := func(err error) handImport {
+		return failedHand(filename, handBytes, p.enc, pos, locateErr(err, filename, metadata.ID, handBytes, p.enc, pos))
+	}
+
+	// a hand fails with its first problem, lines that could not be parsed only become warnings if the rest of the
+	// hand parses
+	lineErr := firstLineErr(p.warnings)
 
-		// update summary.Winners with scanHandLines extracted winners
-		summary.Winners = append(summary.Winners, winners...)
+	if p.section != sectionSummary {
+		return fail(cmp.Or(lineErr, lineError(KindSummary, ErrNoSummary, nil, 0, 0))) // the hand lacks important summary data
+	}
+
+	if p.potErr != nil {
+		return fail(cmp.Or(lineErr, p.potErr)) // the hand lacks important summary data
+	}
 
-		handChan <- handImport{
-			filePath: filename,
-			hand: Hand{
-				Metadata: metadata,
-				Players:  players,
-				Actions:  actions,
-				Summary:  summary,
-			},
-			handErr: nil,
-			fileErr: false,
+	hand := p.hand()
+
+	if len(p.warnings) > 0 {
+		hand.Warnings = make([]*ParseError, len(p.warnings))
+		for i, w := range p.warnings {
+			hand.Warnings[i] = locateErr(w, filename, metadata.ID, handBytes, p.enc, pos)
 		}
 	}
 
-	if err := fileData.Err(); err != nil {
-		return false, fmt.Errorf("Invalid input: %s", err)
+	if lineErr != nil {
+		// kept only by ParseLenient, with lineErr and any others among the hand's warnings
+		h := failedHand(filename, handBytes, p.enc, pos, lineErr)
+		h.hand = hand
+		h.partial = true
+		return h
 	}
 
-	return true, nil
+	return handImport{
+		filePath: filename,
+		hand:     hand,
+		handErr:  nil,
+		fileErr:  false,
+	}
 }
 
-// parseHandSummary pulls together the hand summary information and metadata.
-func parseHandSummary(summaryText []byte) (Summary, error) {
-	communityCards := parseCommunityCards(summaryText)
+// hand returns the hand the parser has parsed, copied out of the slices it reuses.
+func (p *handParser) hand() Hand {
+	hand := Hand{
+		Metadata: p.metadata,
+		Players:  sortedBySeat(p.players),
+		Summary:  p.summary,
+	}
+	if len(p.actions) > 0 {
+		hand.Actions = slices.Clone(p.actions)
+	}
+	hand.Summary.Winners = append([]Winner{}, p.winners...)
+	return hand
+}
+
+// scanHandLines parses handText line by line, from the section the parser is in. Lines that can't be parsed are
+// skipped and kept as warnings, along with any lines that were not recognised. Warnings are *ParseErrors positioned
+// within the hand. An error is returned only when the hand's metadata can't be parsed, which ends the scan.
+func (p *handParser) scanHandLines(handText []byte) error {
+	for start, lineIdx := 0, 0; start <= len(handText); lineIdx++ {
+		end := bytes.IndexByte(handText[start:], '\n')
+		if end == -1 {
+			end = len(handText)
+		} else {
+			end += start
+		}
+
+		line := handText[start:end]
+		p.lineIdx, p.offset = lineIdx, int64(start)
+		start = end + 1
+		if len(line) == 0 {
+			continue
+		}
 
-	pot, rake, potErr := potFromText(summaryText)
-	if potErr != nil {
-		return Summary{}, potErr
+		if err := p.parseLine(line); err != nil {
+			return err
+		}
 	}
 
-	summary := Summary{communityCards, pot, rake, []Winner{}}
-	return summary, nil
+	switch p.section {
+	case sectionHeader:
+		return lineError(KindNoHandID, NoHandIDError("the hand has no header"), nil, 0, 0)
+	case sectionTable:
+		return p.noButton()
+	}
+	return nil
 }
 
-func parseMetaData(handText []byte) (Metadata, error) {
-	handID := handIDFromText(handText)
-	if handID == nil {
-		return Metadata{}, NoHandIDError(fmt.Sprintf("in hand %#v", handText))
+// parseLine parses a line according to the section of the hand the parser is in, moving on to the next section at
+// the line that starts it.
+func (p *handParser) parseLine(line []byte) error {
+	switch p.section {
+	case sectionHeader:
+		return p.parseHeader(line)
+	case sectionTable:
+		return p.parseTable(line)
+	case sectionSeats:
+		if bytes.HasPrefix(line, seatPrefix) {
+			p.parseSeatLine(line)
+			return nil
+		}
+		p.section = sectionStreets // the seats end at the first line of the betting, usually a blind being posted
+		p.parseStreetLine(line)
+	case sectionStreets:
+		p.parseStreetLine(line)
+	case sectionShowdown:
+		p.parseShowdownLine(line)
+	case sectionSummary:
+		p.parseSummaryLine(line)
 	}
-	dateTime := parseDateTime(dateTimeFromText(handText))
+	return nil
+}
 
-	btnSeatInt, err := extractButtonSeatFromText(handText)
+// parseHeader parses the hand ID and date from the first line of a hand.
+func (p *handParser) parseHeader(line []byte) error {
+	p.header, p.headerIdx, p.headerOffset = line, p.lineIdx, p.offset
 
-	if err != nil {
-		return Metadata{}, err
+	handID := handIDFromText(line)
+	if len(handID) == 0 {
+		return lineError(KindNoHandID, NoHandIDError("the hand header has no hand number"), line, p.lineIdx, p.offset)
 	}
 
-	metadata := Metadata{string(handID), dateTime, int(btnSeatInt)}
-	return metadata, nil
+	p.metadata.ID = string(handID)
+	p.metadata.Date = parseDateTime(dateTimeFromText(line))
+	p.metadata.Site = SitePokerStars
+	p.section = sectionTable
+	return nil
 }
 
-func extractButtonSeatFromText(handBytes []byte) (int64, error) {
-	btnSeatString := substringBetween(handBytes, []byte("Seat #"), []byte(" is the button"))
-	btnSeatInt, err := strconv.ParseInt(string(btnSeatString), 10, 32)
-	return btnSeatInt, err
+// parseTable parses the table name and the button seat from the line following the header.
+func (p *handParser) parseTable(line []byte) error {
+	switch {
+	case bytes.HasPrefix(line, tableSignifier):
+		if !bytes.Contains(line, buttonSignifier) {
+			return p.noButton()
+		}
+
+		btnSeatInt, err := extractButtonSeatFromText(line)
+		if err != nil {
+			return lineError(KindMetadata, err, line, p.lineIdx, p.offset)
+		}
+
+		p.metadata.ButtonSeat = int(btnSeatInt)
+		p.metadata.Table = p.intern(tableFromText(line))
+		p.section = sectionSeats
+	case bytes.HasPrefix(line, seatPrefix):
+		return p.noButton() // the seats have started without a table line
+	default:
+		p.warn(KindUnrecognised, ErrUnrecognisedLine, line)
+	}
+	return nil
 }
 
-// scanHandLines scans the hand data line by line and generates a slice of players, actions and winners. Returns
-// a non-nil error if an error was received from the parse helper functions.
-func scanHandLines(handText []byte) ([]Player, []Action, []Winner, error) {
+// noButton returns the metadata error of a hand whose button seat was not found, reported at its header.
+func (p *handParser) noButton() *ParseError {
+	return lineError(KindMetadata, ErrNoButton, p.header, p.headerIdx, p.headerOffset)
+}
 
-	playersMap := map[string]Player{}
-	var actions []Action
-	var winners []Winner
-	var street = Preflop
-	var order = 0
-	var showDownState = noShowdown
+// parseSeatLine parses a player's seat and stack from a line of the seats.
+func (p *handParser) parseSeatLine(line []byte) {
+	player, found, err := p.parseSeat(line)
+	switch {
+	case err != nil:
+		p.warn(KindPlayer, err, line)
+	case found:
+		p.updateOrAddPlayer(player)
+	default:
+		p.warn(KindUnrecognised, ErrUnrecognisedLine, line)
+	}
+}
 
-	lines := bytes.SplitSeq(handText, newLine)
-	for line := range lines {
-		if len(line) == 0 {
-			continue
+// parseStreetLine parses a line of the betting: an action, the hero's hole cards, or the marker of the next street
+// or section.
+func (p *handParser) parseStreetLine(line []byte) {
+	switch {
+	case bytes.HasPrefix(line, markerPrefix):
+		p.startSection(line)
+	case bytes.HasPrefix(line, heroHandPrefix):
+		if p.metadata.Hero == "" {
+			p.metadata.Hero = p.intern(heroFromText(line))
 		}
 
+		player, _, err := p.parseHoleCards(line)
+		if err != nil {
+			p.warn(KindPlayer, err, line)
+			return
+		}
+		p.updateOrAddPlayer(player)
+	case bytes.HasPrefix(line, uncalledBetPrefix):
+		// the uncalled bet returned to a player, the parser records the actions it came from
+	default:
+		action, found, err := p.parseActionLine(line)
 		switch {
-		case bytes.Contains(line, showDownSignfier):
-			showDownState = rio
-		case bytes.Contains(line, firstShowDownSignifier):
-			showDownState = ritFirstBoard
-		case bytes.Contains(line, secondShowDownSignifier):
-			showDownState = ritSecondBoard
+		case err != nil:
+			p.warn(KindAction, err, line)
+		case found:
+			p.actions = append(p.actions, action)
+		case !collectedFromPot(line) && !shownHand(line):
+			p.warn(KindUnrecognised, ErrUnrecognisedLine, line)
 		}
+	}
+}
 
-		actionResult, actionFound, actionErr := parseActionLine(line, &street, &order)
+// startSection moves the parser on at a marker line, which starts a street or a section of the hand.
+func (p *handParser) startSection(line []byte) {
+	switch {
+	case bytes.HasPrefix(line, flopSignifier):
+		p.street = Flop
+	case bytes.HasPrefix(line, turnSignifier):
+		p.street = Turn
+	case bytes.HasPrefix(line, riverSignifier):
+		p.street = River
+	case bytes.HasPrefix(line, showDownSignfier), bytes.HasPrefix(line, firstShowDownSignifier):
+		p.section, p.board = sectionShowdown, 1
+	case bytes.HasPrefix(line, secondShowDownSignifier):
+		p.section, p.board = sectionShowdown, 2
+	case bytes.HasPrefix(line, summarySignifier):
+		p.section = sectionSummary
+	}
+}
 
-		if actionErr != nil {
-			return nil, nil, nil, actionErr
-		}
+// parseShowdownLine parses a line of the showdown, where players show their cards and collect the pots.
+func (p *handParser) parseShowdownLine(line []byte) {
+	if bytes.HasPrefix(line, markerPrefix) {
+		p.startSection(line)
+		return
+	}
 
-		if actionFound {
-			actions = append(actions, actionResult)
+	winner, found, err := p.winnerFromLine(line, p.board)
+	switch {
+	case err != nil:
+		p.warn(KindWinner, err, line)
+	case found:
+		p.winners = append(p.winners, winner)
+	case !shownHand(line):
+		p.warn(KindUnrecognised, ErrUnrecognisedLine, line)
+	}
+}
+
+// parseSummaryLine parses a line of the summary: the pot and rake, a board or a seat's result.
+func (p *handParser) parseSummaryLine(line []byte) {
+	switch {
+	case bytes.HasPrefix(line, seatPrefix):
+		p.parseSummarySeatLine(line)
+	case bytes.HasPrefix(line, potSizeSignifier):
+		pot, rake, err := potFromText(line)
+		if err != nil {
+			p.potErr = cmp.Or(p.potErr, lineError(KindSummary, err, line, p.lineIdx, p.offset))
+			return
 		}
+		p.summary.Pot, p.summary.Rake = pot, rake
+	case bytes.HasPrefix(line, boardSignifier):
+		p.summary.CommunityCards[0] = communityCardsFromText(line, boardSignifier)
+	case bytes.HasPrefix(line, ritFirstBoardSignifier):
+		p.summary.CommunityCards[0] = communityCardsFromText(line, ritFirstBoardSignifier)
+	case bytes.HasPrefix(line, ritSecondBoardSignifier):
+		p.summary.CommunityCards[1] = communityCardsFromText(line, ritSecondBoardSignifier)
+	case bytes.HasPrefix(line, runTwiceSignifier):
+	default:
+		p.warn(KindUnrecognised, ErrUnrecognisedLine, line)
+	}
+}
 
-		player, playerFound, parsePlayerErr := parsePlayer(line)
+// parseSummarySeatLine parses a seat's result from the summary, and the pot won by a player when there was no
+// showdown.
+func (p *handParser) parseSummarySeatLine(line []byte) {
+	player, playerFound, err := p.parseSummarySeat(line)
+	if err != nil {
+		p.warn(KindPlayer, err, line)
+		return
+	}
+	if playerFound {
+		p.updateOrAddPlayer(player)
+	}
 
-		if parsePlayerErr != nil {
-			return nil, nil, nil, parsePlayerErr
+	winnerFound := false
+	if p.board == 0 {
+		var winner Winner
+		winner, winnerFound, err = p.noShowdownWinner(line)
+		if err != nil {
+			p.warn(KindWinner, err, line)
+			return
 		}
+		if winnerFound {
+			p.winners = append(p.winners, winner)
+		}
+	}
+
+	if !playerFound && !winnerFound && !bytes.Contains(line, wonSignifier) {
+		p.warn(KindUnrecognised, ErrUnrecognisedLine, line)
+	}
+}
+
+// warn keeps a warning for the line being parsed.
+func (p *handParser) warn(kind ParseErrorKind, err error, line []byte) {
+	p.warnings = append(p.warnings, lineError(kind, err, line, p.lineIdx, p.offset))
+}
+
+// intern returns text as a string, allocating it only the first time the parser sees it. The names of the players
+// and tables of a file repeat from hand to hand.
+func (p *handParser) intern(text []byte) string {
+	if s, ok := p.names[string(text)]; ok {
+		return s
+	}
 
-		if playerFound {
-			updateOrAddPlayer(playersMap, player)
+	if p.names == nil {
+		p.names = map[string]string{}
+	}
+	s := string(text)
+	p.names[s] = s
+	return s
+}
+
+// tooLargeHand returns the handImport of a hand found at pos in filename that is larger than maxHandSize, of which
+// handBytes holds the start. The hand fails without being parsed.
+func tooLargeHand(filename string, handBytes []byte, enc fileEncoding, pos filePos) handImport {
+	header, _, _ := bytes.Cut(handBytes, newLine)
+	err := lineError(KindTooLarge, ErrHandTooLarge, nil, 0, 0)
+	return failedHand(filename, handBytes, enc, pos, locateErr(err, filename, string(handIDFromText(header)), handBytes, enc, pos))
+}
+
+// firstLineErr returns the first of warnings for a line that could not be parsed, as opposed to one that was not
+// recognised, or nil if there is none.
+func firstLineErr(warnings []*ParseError) *ParseError {
+	for _, w := range warnings {
+		if w.Kind != KindUnrecognised {
+			return w
 		}
+	}
+	return nil
+}
+
+// failedHand returns the handImport of a hand found at pos in filename that failed to parse with err. A copy of the
+// hand's text is kept for the quarantine, with any "PokerStars " consumed as part of the hand delimiter restored.
+func failedHand(filename string, handBytes []byte, enc fileEncoding, pos filePos, err error) handImport {
+	var prefix []byte
+	if pos.offset > 0 && !bytes.HasPrefix(handBytes, handDelimiter[1:]) {
+		prefix = handDelimiter[1:]
+		pos.offset -= enc.size(prefix)
+	}
+
+	return handImport{
+		filePath:  filename,
+		handErr:   err,
+		handText:  slices.Concat(prefix, handBytes),
+		handStart: pos,
+	}
+}
+
+// locateErr fills in the file position of a *ParseError returned while parsing handBytes, found at pos in file,
+// which is in enc.
+func locateErr(err error, file, handID string, handBytes []byte, enc fileEncoding, pos filePos) *ParseError {
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		parseErr = lineError(KindMetadata, err, nil, 0, 0)
+	}
+	parseErr.Offset = enc.size(handBytes[:min(parseErr.Offset, int64(len(handBytes)))])
 
-		w, winnerErr := extractWinners(line, showDownState, street)
-		if winnerErr != nil {
-			return nil, nil, nil, winnerErr
+	// hands after the first in a file begin after the "PokerStars " consumed as part of the hand delimiter
+	if parseErr.Line == 1 && pos.offset > 0 && !bytes.HasPrefix(handBytes, handDelimiter[1:]) {
+		parseErr.Offset -= enc.size(handDelimiter[1:])
+		if parseErr.Text != "" {
+			parseErr.Text = string(handDelimiter[1:]) + parseErr.Text
 		}
+	}
+	return parseErr.locate(file, handID, pos)
+}
 
-		winners = append(winners, w...)
+// tableFromText returns the table name from the hand info, or nil if there is none
+func tableFromText(handText []byte) []byte {
+	_, after, found := bytes.Cut(handText, tableSignifier)
+	if !found {
+		return nil
+	}
+
+	name, _, found := bytes.Cut(after, []byte("' "))
+	if !found {
+		return nil
 	}
+	return name
+}
 
-	playersSlice := convertToSlice(playersMap)
+// heroFromText returns the name of the player hole cards were dealt to, or nil if there is none
+func heroFromText(handText []byte) []byte {
+	_, after, found := bytes.Cut(handText, heroHandPrefix)
+	if !found {
+		return nil
+	}
 
-	return playersSlice, actions, winners, nil
+	name, _, found := bytes.Cut(after, []byte(" ["))
+	if !found {
+		return nil
+	}
+	return bytes.TrimSpace(name)
 }
 
-// converToSlice takes a playerMap and returns a []Player ordered by seat position
-func convertToSlice(playersMap map[string]Player) []Player {
-	playersSlice := make([]Player, len(playersMap))
-	i := 0
-	for _, v := range playersMap {
-		playersSlice[i] = v
-		i++
+func extractButtonSeatFromText(handBytes []byte) (int64, error) {
+	return parseSeatNumber(substringBetween(handBytes, []byte("Seat #"), buttonSignifier))
+}
+
+// sortedBySeat returns a copy of players ordered by seat position
+func sortedBySeat(players []Player) []Player {
+	playersSlice := slices.Clone(players)
+	if playersSlice == nil {
+		playersSlice = []Player{}
 	}
-	slices.SortFunc(playersSlice, func(a, b Player) int {
+	slices.SortStableFunc(playersSlice, func(a, b Player) int {
 		return cmp.Compare(a.Seat, b.Seat)
 	})
 	return playersSlice
@@ -271,72 +720,102 @@ func convertToSlice(playersMap map[string]Player) []Player {
 // parseActionLine checks a line of text for a poker action and if found returns an action, along
 // with true bool and nil error. If there is no action found, an empty Action struct will be returned,
 // along with a false bool. If there was an error parsing an action detail a non-nil error will be returned.
-func parseActionLine(line []byte, actionStreet *Street, order *int) (Action, bool, error) {
-	actionStreet.next(line)
-	actionType, actionFound := actionTypeFromText(line)
-
-	if !actionFound {
-		return Action{}, actionFound, nil
+func (p *handParser) parseActionLine(line []byte) (Action, bool, error) {
+	playerName, verb, found := bytes.Cut(line, nameSeparator)
+	if !found {
+		// only a malformed action has no player name before it
+		if _, actionFound := actionTypeFromText(line); !actionFound {
+			return Action{}, false, nil
+		}
+		if _, playerErr := actionPlayerNameFromText(line); playerErr != nil {
+			return Action{}, true, ActionParseError(playerErr.Error())
+		}
+		return Action{}, true, ActionParseError("no action found after the player name")
 	}
 
-	playerName, playerErr := actionPlayerNameFromText(line)
-
-	actionStartIdx := bytes.Index(line, []byte(": "))
-	amount, amtErr := actionAmountFromText(line[actionStartIdx:])
-
-	if playerErr != nil {
-		return Action{}, actionFound, ActionParseError(fmt.Sprintf("%v %v", playerErr, line))
+	actionType, actionFound := actionTypeFromVerb(verb)
+	if !actionFound {
+		return Action{}, false, nil
 	}
 
-	if amtErr != nil {
-		return Action{}, actionFound, ActionParseError(fmt.Sprintf("%v %v", amtErr, line))
+	var amount float64
+	if actionType != ActionFold && actionType != ActionCheck {
+		var amtErr error
+		if amount, amtErr = extractAmount(verb); amtErr != nil {
+			return Action{}, true, ActionParseError(amtErr.Error())
+		}
 	}
 
-	*order++
+	p.order++
 
 	return Action{
 		ActionType: actionType,
-		PlayerName: string(playerName),
-		Street:     *actionStreet,
-		Order:      *order,
+		PlayerName: p.intern(playerName),
+		Street:     p.street,
+		Order:      p.order,
 		Amount:     amount,
-	}, actionFound, nil
+	}, true, nil
 }
 
-func parseCommunityCards(handText []byte) [2]CommunityCards {
-	if bytes.Contains(handText, []byte("Hand was run twice")) {
-		firstBoard := communityCardsFromText(handText, ritFirstBoardSignifier)
-		secondBoard := communityCardsFromText(handText, ritSecondBoardSignifier)
-		return [2]CommunityCards{firstBoard, secondBoard}
-	}
-	if bytes.Contains(handText, boardSignifier) {
-		board := communityCardsFromText(handText, boardSignifier)
-		return [2]CommunityCards{board, {}}
+// actionTypeFromVerb returns the action of an action line from the verb following the player's name.
+func actionTypeFromVerb(verb []byte) (ActionType, bool) {
+	for _, a := range actionVerbs {
+		if bytes.HasPrefix(verb, a.verb) {
+			return a.actionType, true
+		}
 	}
-	return [2]CommunityCards{}
+	return "", false
+}
+
+// shownHand reports whether line is a player showing, mucking or not showing their cards.
+func shownHand(line []byte) bool {
+	_, verb, found := bytes.Cut(line, nameSeparator)
+	return found && slices.ContainsFunc(shownHandVerbs, func(v []byte) bool { return bytes.HasPrefix(verb, v) })
+}
+
+// collectedFromPot reports whether line is a player collecting a pot, the main pot or a side pot.
+func collectedFromPot(line []byte) bool {
+	return bytes.Contains(line, collectedSignifier) &&
+		slices.ContainsFunc(collectedPotSignifiers, func(sig []byte) bool { return bytes.Contains(line, sig) })
 }
 
 func communityCardsFromText(handText, boardStart []byte) CommunityCards {
 	boardString := substringBetween(handText, boardStart, []byte("]"))
-	fields := bytes.Fields(boardString)
+
+	var fields [5]Card
+	n := 0
+	for rest := bytes.TrimSpace(boardString); len(rest) > 0 && n < len(fields); n++ {
+		var field []byte
+		field, rest, _ = bytes.Cut(rest, []byte(" "))
+		fields[n] = card(field)
+		rest = bytes.TrimLeft(rest, " ")
+	}
 
 	cc := CommunityCards{}
 
-	if len(fields) >= 3 {
-		cc.Flop = [3]Card{Card(fields[0]), Card(fields[1]), Card(fields[2])}
+	if n >= 3 {
+		cc.Flop = [3]Card{fields[0], fields[1], fields[2]}
 	}
 
-	if len(fields) >= 4 {
-		cc.Turn = Card(fields[3])
+	if n >= 4 {
+		cc.Turn = fields[3]
 	}
 
-	if len(fields) >= 5 {
-		cc.River = Card(fields[4])
+	if n >= 5 {
+		cc.River = fields[4]
 	}
 
 	return cc
 }
 
+// card returns the Card written as text, without allocating for any of the 52 cards of a deck.
+func card(text []byte) Card {
+	if c, ok := deck[string(text)]; ok {
+		return c
+	}
+	return Card(text)
+}
+
 func actionTypeFromText(line []byte) (ActionType, bool) {
 	switch {
 	case bytes.Contains(line, sigFolds):
@@ -364,16 +843,6 @@ func actionPlayerNameFromText(line []byte) ([]byte, error) {
 	return before, nil
 }
 
-// actionAmountFromText returns the monetary amount of the action. Returns an error if no currency found.
-func actionAmountFromText(line []byte) (float64, error) {
-
-	if bytes.Contains(line, []byte("checks")) || bytes.Contains(line, []byte("folds")) {
-		return 0, nil
-	}
-
-	return extractAmount(line)
-}
-
 func extractAmount(line []byte) (float64, error) {
 
 	i := bytes.IndexByte(line, '$')
@@ -388,7 +857,35 @@ func extractAmount(line []byte) (float64, error) {
 		j++
 	}
 
-	return strconv.ParseFloat(string(line[i+1:j]), 64)
+	return parseAmount(line[i+1 : j])
+}
+
+// parseAmount parses the digits and decimal point of an amount as strconv.ParseFloat does, without allocating. An
+// amount of up to 15 digits divided by a power of ten is exact before the division, so the division rounds it just
+// as ParseFloat would. Longer or malformed amounts are left to ParseFloat.
+func parseAmount(num []byte) (float64, error) {
+	var mantissa uint64
+	digits, decimals, point := 0, 0, false
+
+	for _, c := range num {
+		switch {
+		case c >= '0' && c <= '9':
+			mantissa = mantissa*10 + uint64(c-'0')
+			digits++
+			if point {
+				decimals++
+			}
+		case c == '.' && !point:
+			point = true
+		default:
+			return strconv.ParseFloat(string(num), 64)
+		}
+	}
+
+	if digits == 0 || digits >= len(pow10) {
+		return strconv.ParseFloat(string(num), 64)
+	}
+	return float64(mantissa) / pow10[decimals], nil
 }
 
 // handIDFromText returns the hand ID string from the hand info string
@@ -404,39 +901,23 @@ func handIDFromText(handText []byte) []byte {
 	return nil
 }
 
-func parsePlayer(line []byte) (Player, bool, error) {
-
-	// Found chips, extract name, seat num and chips
-	if bytes.Contains(line, []byte(" in chips)")) {
-		return extractChipsAndSeatInt(line)
+// parseSeat parses a line of the seats, returning the player's seat and stack. Found is false for a line that does
+// not give a stack.
+func (p *handParser) parseSeat(line []byte) (Player, bool, error) {
+	if !bytes.Contains(line, []byte(" in chips)")) {
+		return Player{}, false, nil
 	}
 
-	// Found hero hand extracting name, cards
-	if bytes.Contains(line, heroHandPrefix) {
-		return heroHandFromText(line)
-	}
-
-	if bytes.Contains(line, showedSignifier) {
-		return playerInfoFromText(line, showedSignifier)
-	}
-
-	if bytes.Contains(line, muckedSignifier) {
-		return playerInfoFromText(line, muckedSignifier)
-	}
-
-	if bytes.Contains(line, foldedSignifier) || bytes.Contains(line, collectedSummarySignifier) {
-		return playerInfoFromText(line, nil)
-	}
-
-	return Player{}, false, nil
-}
-
-func extractChipsAndSeatInt(line []byte) (Player, bool, error) {
 	seatInt, seatIntErr := seatIntFromText(line)
-	playerName := substringBetween(line, []byte(": "), []byte(" ("))
+	playerName := substringBetween(line, nameSeparator, []byte(" ("))
 
-	chipCountIdx := bytes.Index(line, []byte("($"))
-	chipCount, chipCountErr := extractAmount(line[chipCountIdx:])
+	var chipCount float64
+	var chipCountErr error
+	if chipCountIdx := bytes.Index(line, []byte("($")); chipCountIdx == -1 {
+		chipCountErr = CurrencyError(fmt.Sprintf("on line %v", string(line))) // play money chips have no currency
+	} else {
+		chipCount, chipCountErr = extractAmount(line[chipCountIdx:])
+	}
 
 	if seatIntErr != nil {
 		return Player{}, false, seatIntErr
@@ -445,7 +926,7 @@ func extractChipsAndSeatInt(line []byte) (Player, bool, error) {
 		return Player{}, false, chipCountErr
 	}
 	return Player{
-			Username:  string(playerName),
+			Username:  p.intern(playerName),
 			Seat:      int(seatInt),
 			ChipCount: chipCount,
 		},
@@ -453,8 +934,23 @@ func extractChipsAndSeatInt(line []byte) (Player, bool, error) {
 		nil
 }
 
-func playerInfoFromText(line []byte, cardPrefix []byte) (Player, bool, error) {
-	playerName := substringBetween(line, []byte(": "), []byte(" "))
+// parseSummarySeat parses a seat's result from the summary, returning the player along with any cards they showed
+// or mucked. Found is false for results that do not name the player, such as a player winning at showdown.
+func (p *handParser) parseSummarySeat(line []byte) (Player, bool, error) {
+	switch {
+	case bytes.Contains(line, showedSignifier):
+		return p.playerInfoFromText(line, showedSignifier)
+	case bytes.Contains(line, muckedSignifier):
+		return p.playerInfoFromText(line, muckedSignifier)
+	case bytes.Contains(line, foldedSignifier) || bytes.Contains(line, collectedSummarySignifier):
+		return p.playerInfoFromText(line, nil)
+	default:
+		return Player{}, false, nil
+	}
+}
+
+func (p *handParser) playerInfoFromText(line []byte, cardPrefix []byte) (Player, bool, error) {
+	playerName := substringBetween(line, nameSeparator, []byte(" "))
 
 	cards := [2]Card{}
 
@@ -462,100 +958,91 @@ func playerInfoFromText(line []byte, cardPrefix []byte) (Player, bool, error) {
 		cardString := substringBetween(line, cardPrefix, []byte("]"))
 		before, after, ok := bytes.Cut(cardString, []byte(" "))
 		if ok {
-			cards[0] = Card(before)
-			cards[1] = Card(after)
+			cards[0] = card(before)
+			cards[1] = card(after)
 		} else {
 			return Player{}, false, PlayerInfoError(fmt.Sprintf("not enough fields on line %s, expected 2 fields for cards", string(line)))
 		}
 	}
 
 	return Player{
-			Username: string(playerName),
+			Username: p.intern(playerName),
 			Cards:    cards,
 		},
 		true,
 		nil
 }
 
-func heroHandFromText(line []byte) (Player, bool, error) {
+// parseHoleCards parses the hole cards dealt to the hero.
+func (p *handParser) parseHoleCards(line []byte) (Player, bool, error) {
 	playerName := substringBetween(line, []byte("Dealt to "), []byte(" ["))
 	cards := [2]Card{}
 
 	cardString := substringBetween(line, []byte("["), []byte("]"))
 	before, after, ok := bytes.Cut(cardString, []byte(" "))
 	if ok {
-		cards[0] = Card(before)
-		cards[1] = Card(after)
+		cards[0] = card(before)
+		cards[1] = card(after)
 	} else {
 		return Player{}, false, PlayerInfoError(fmt.Sprintf("not enough fields on line %s, expected 2 fields for cards", string(line)))
 	}
 
 	return Player{
-			Username: string(playerName),
+			Username: p.intern(playerName),
 			Cards:    cards,
 		},
 		true,
 		nil
 }
 
-func extractWinners(line []byte, showdownState ShowdownState, street Street) ([]Winner, error) {
-	switch showdownState {
-	case noShowdown:
-		return noShowdownWinner(line, street)
-	case rio, ritFirstBoard:
-		return winnerFromLine(line, 1)
-	case ritSecondBoard:
-		return winnerFromLine(line, 2)
-	default:
-		return []Winner{}, fmt.Errorf("extractWinners: unhandled showdown state %d", showdownState)
-	}
-}
-
-func noShowdownWinner(line []byte, street Street) ([]Winner, error) {
+// noShowdownWinner parses the pot collected by a player in the summary of a hand without a showdown. The pot is
+// won on no board when everyone folded before the flop.
+func (p *handParser) noShowdownWinner(line []byte) (Winner, bool, error) {
 	if !bytes.Contains(line, collectedSummarySignifier) {
-		return []Winner{}, nil
+		return Winner{}, false, nil
 	}
 
 	amount, amountErr := extractAmount(substringBetween(line, collectedSummarySignifier, []byte(")")))
 	if amountErr != nil {
-		return []Winner{}, amountErr
+		return Winner{}, false, amountErr
 	}
 
 	playerName := playerNameFromSummaryLine(line, collectedSummarySignifier)
 
 	boardNum := 0
-	if street != Preflop {
+	if p.street != Preflop {
 		boardNum = 1
 	}
 
-	return []Winner{{
-		PlayerName: string(playerName),
+	return Winner{
+		PlayerName: p.intern(playerName),
 		Amount:     amount,
 		Board:      boardNum,
-	}}, nil
+	}, true, nil
 }
 
-func winnerFromLine(line []byte, boardNum int) ([]Winner, error) {
-	if !bytes.Contains(line, []byte(" collected ")) || !bytes.Contains(line, []byte(" from pot")) {
-		return []Winner{}, nil
+// winnerFromLine parses a pot collected by a player at showdown on board boardNum.
+func (p *handParser) winnerFromLine(line []byte, boardNum int) (Winner, bool, error) {
+	if !collectedFromPot(line) {
+		return Winner{}, false, nil
 	}
 
-	amount, amountErr := extractAmount(substringBetween(line, []byte(" collected "), []byte(" from pot")))
+	amount, amountErr := extractAmount(substringBetween(line, collectedSignifier, []byte(" from ")))
 	if amountErr != nil {
-		return []Winner{}, amountErr
+		return Winner{}, false, amountErr
 	}
 
 	name, _, _ := bytes.Cut(line, []byte(" "))
 
-	return []Winner{{
-		PlayerName: string(name),
+	return Winner{
+		PlayerName: p.intern(name),
 		Amount:     amount,
 		Board:      boardNum,
-	}}, nil
+	}, true, nil
 }
 
 func playerNameFromSummaryLine(line, trigger []byte) []byte {
-	contentBeforeTrigger := substringBetween(line, []byte(": "), trigger)
+	contentBeforeTrigger := substringBetween(line, nameSeparator, trigger)
 	before, _, ok := bytes.Cut(contentBeforeTrigger, []byte(" "))
 	if !ok {
 		return contentBeforeTrigger
@@ -564,40 +1051,80 @@ func playerNameFromSummaryLine(line, trigger []byte) []byte {
 }
 
 func seatIntFromText(line []byte) (int64, error) {
-	if !bytes.HasPrefix(line, []byte("Seat ")) {
+	if !bytes.HasPrefix(line, seatPrefix) {
 		return 0, PlayerInfoError(fmt.Sprintf("no matches for seatInt found on line %v", string(line)))
 	}
 
-	i := len("Seat ")
+	i := len(seatPrefix)
 	j := i
 	for j < len(line) && line[j] >= '0' && line[j] <= '9' {
 		j++
 	}
 
-	return strconv.ParseInt(string(line[i:j]), 10, 32)
+	return parseSeatNumber(line[i:j])
+}
 
+// parseSeatNumber parses a seat number as strconv.ParseInt does, without allocating unless it is not a number.
+func parseSeatNumber(num []byte) (int64, error) {
+	n, ok := atoi(num)
+	if !ok {
+		return strconv.ParseInt(string(num), 10, 32)
+	}
+	return int64(n), nil
 }
 
-func parseDateTime(timeString []byte) time.Time {
-	siteTime, _ := time.ParseInLocation(time.DateTime, string(timeString), siteLocation)
-	return siteTime.UTC()
+// atoi parses num, of one to nine digits, reporting whether it could.
+func atoi(num []byte) (int, bool) {
+	if len(num) == 0 || len(num) > 9 {
+		return 0, false
+	}
+
+	n := 0
+	for _, c := range num {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n, true
 }
 
-// dateTimeFromText extracts the relevant time from the hand information
-// and converts to a string that can be transformed into a time.Time
-func dateTimeFromText(line []byte) []byte {
+// parseDateTime parses a hand's time in the site's time zone, written as 2025/01/21 15:51:32 or with the date
+// separated by dashes, returning it in UTC. The zero time is returned if it can't be parsed.
+func parseDateTime(timeString []byte) time.Time {
+	date, clock, found := bytes.Cut(timeString, []byte(" "))
+	if !found || len(date) != len(time.DateOnly) || date[4] != date[7] || (date[4] != '/' && date[4] != '-') {
+		return time.Time{}
+	}
 
-	var timeString []byte
-	if bytes.ContainsAny(line, "[]") {
-		timeString = substringBetween(line, []byte("["), []byte(" ET]"))
+	// the hour may have a single digit
+	hourText, minSec, found := bytes.Cut(clock, []byte(":"))
+	if !found || len(hourText) > 2 || len(minSec) != len("04:05") || minSec[2] != ':' {
+		return time.Time{}
 	}
 
-	for i := range timeString {
-		if timeString[i] == '/' {
-			timeString[i] = '-'
-		}
+	year, yearOK := atoi(date[:4])
+	month, monthOK := atoi(date[5:7])
+	day, dayOK := atoi(date[8:])
+	hour, hourOK := atoi(hourText)
+	minute, minuteOK := atoi(minSec[:2])
+	second, secondOK := atoi(minSec[3:])
+	if !yearOK || !monthOK || !dayOK || !hourOK || !minuteOK || !secondOK ||
+		month < 1 || month > 12 || hour > 23 || minute > 59 || second > 59 {
+		return time.Time{}
+	}
+
+	siteTime := time.Date(year, time.Month(month), day, hour, minute, second, 0, siteLocation)
+	if siteTime.Day() != day {
+		return time.Time{} // the day is past the end of the month
 	}
-	return timeString
+	return siteTime.UTC()
+}
+
+// dateTimeFromText returns the time of the hand in the site's time zone from the hand's header, e.g.
+// 2025/01/21 15:51:32, or nil if there is none.
+func dateTimeFromText(line []byte) []byte {
+	return substringBetween(line, []byte("["), []byte(" ET]"))
 }
 
 func potFromText(handBytes []byte) (float64, float64, error) {
@@ -618,30 +1145,31 @@ func potFromText(handBytes []byte) (float64, float64, error) {
 	return 0, 0, nil
 }
 
-func updateOrAddPlayer(players map[string]Player, player Player) {
-	if p, ok := players[player.Username]; ok {
-		if p.Cards[0] == "" {
-			p.Cards = player.Cards
-			players[player.Username] = p
-		}
-	} else {
-		newPlayer := player
-		players[player.Username] = newPlayer
+// updateOrAddPlayer adds player to the players of the hand, or gives the cards of player to the player of the same
+// name if their cards are not yet known.
+func (p *handParser) updateOrAddPlayer(player Player) {
+	i := slices.IndexFunc(p.players, func(existing Player) bool { return existing.Username == player.Username })
+	if i == -1 {
+		p.players = append(p.players, player)
+		return
+	}
+
+	if p.players[i].Cards[0] == "" {
+		p.players[i].Cards = player.Cards
 	}
 }
 
-// subStringBetween returns the substring between the first instance of characters start and end.
-// If text does not contain either the start or end string, the original text is returned unchanged.
+// subStringBetween returns the substring between the first instance of start and the first instance of end after
+// it. If text does not contain start, or end after start, nil is returned.
 func substringBetween(text, start, end []byte) []byte {
-	startIndex := bytes.Index(text, start)
-	if startIndex == -1 {
-		return text
+	_, afterStart, found := bytes.Cut(text, start)
+	if !found {
+		return nil
 	}
-	startSubString := text[startIndex+len(start):]
-	before, _, ok := bytes.Cut(startSubString, end)
 
-	if !ok || startIndex+len(start) > len(text) {
-		return text
+	before, _, found := bytes.Cut(afterStart, end)
+	if !found {
+		return nil
 	}
 
 	return before
@@ -667,3 +1195,124 @@ func splitByHands() func(data []byte, atEOF bool) (advance int, token []byte, er
 		return 0, nil, nil
 	}
 }
+
+// filePos is a position within a file.
+type filePos struct {
+	offset int64
+	lines  int // newlines before offset, the position is on line lines+1
+}
+
+// handSplitter wraps a hand split function, keeping track of how much input has been consumed, where the latest
+// token starts and whether it was ended by the end of the input rather than a hand delimiter. A hand longer than max
+// is cut short to its first max bytes and marked as too large, and the rest of it is skipped up to the next hand
+// delimiter, so that a reader never has to hold more than max bytes of a hand.
+type handSplitter struct {
+	split    bufio.SplitFunc
+	max      int
+	enc      fileEncoding // the encoding of the file, which positions are measured in
+	consumed filePos
+	tokenPos filePos
+	trailing bool
+	tooLarge bool
+	skipping bool // the rest of a hand that was too large is being skipped
+}
+
+// Split implements bufio.SplitFunc.
+func (s *handSplitter) Split(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if s.skipping {
+		return s.skip(data, atEOF), nil, nil
+	}
+
+	advance, token, err = s.split(data, atEOF)
+	if token != nil {
+		s.tokenPos = s.consumed
+	}
+	s.trailing = atEOF && token != nil && len(token) == advance
+	s.tooLarge = false
+
+	switch {
+	case len(token) > s.max:
+		token, s.tooLarge = token[:s.max], true
+	case token == nil && err == nil && len(data) >= s.max:
+		// no delimiter in max bytes - give up on the hand, skipping the rest of it
+		advance, token = s.max, data[:s.max]
+		s.tokenPos = s.consumed
+		s.tooLarge, s.skipping = true, true
+	}
+
+	s.consume(data[:advance])
+	return advance, token, err
+}
+
+// skip consumes the rest of a hand that was too large, up to and including the next hand delimiter, returning how
+// much of data was consumed.
+func (s *handSplitter) skip(data []byte, atEOF bool) int {
+	advance := max(0, len(data)-len(handDelimiter)+1) // a delimiter may be split across reads
+	if i := bytes.Index(data, handDelimiter); i >= 0 {
+		advance, s.skipping = i+len(handDelimiter), false
+	} else if atEOF {
+		advance = len(data)
+	}
+
+	s.consume(data[:advance])
+	return advance
+}
+
+// consume moves the position past data.
+func (s *handSplitter) consume(data []byte) {
+	s.consumed.offset += s.enc.size(data)
+	s.consumed.lines += bytes.Count(data, newLine)
+}
+
+// handScanner reads the hands of a file, as bufio.Scanner does from a stream, or bytesScanner from a file mapped
+// into memory.
+type handScanner interface {
+	Split(split bufio.SplitFunc)
+	Scan() bool
+	Bytes() []byte
+	Err() error
+}
+
+// bytesScanner scans tokens from data held in memory, as bufio.Scanner does from a reader, but without copying data
+// or limiting the size of a token. Split functions are always called with atEOF true, as all of data is available.
+type bytesScanner struct {
+	data  []byte
+	split bufio.SplitFunc
+	token []byte
+	err   error
+}
+
+// Split sets the split function of the scanner.
+func (s *bytesScanner) Split(split bufio.SplitFunc) {
+	s.split = split
+}
+
+// Scan advances the scanner to the next token, returning false at the end of data or on an error.
+func (s *bytesScanner) Scan() bool {
+	for len(s.data) > 0 && s.err == nil {
+		advance, token, err := s.split(s.data, true)
+		switch {
+		case err != nil:
+			s.err = err
+		case advance <= 0 || advance > len(s.data):
+			s.err = io.ErrNoProgress
+		default:
+			s.data = s.data[advance:]
+			if token != nil {
+				s.token = token
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Bytes returns the latest token, which is a slice of the scanner's data.
+func (s *bytesScanner) Bytes() []byte {
+	return s.token
+}
+
+// Err returns the first error encountered by the scanner.
+func (s *bytesScanner) Err() error {
+	return s.err
+}
@@ -0,0 +1,128 @@
+package hands
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+// benchCorpusHands is the number of hands in benchCorpus
+const benchCorpusHands = 1000
+
+// parseAllocBudget is the most allocations parseHand may make on average for a hand of benchCorpus, parsed by a
+// handParser reused for the file as scanHands does. Lower it as the parser improves, so that a change allocating
+// more per hand is caught before it slows down a large import.
+const parseAllocBudget = 5
+
+// scanAllocBudget is the most allocations scanHandLines may make on average for a hand of benchCorpus: the hand ID,
+// as the lines themselves are parsed without allocating.
+const scanAllocBudget = 1
+
+// benchCorpus returns a file of benchCorpusHands hands, made up of the hands used by the parser tests repeated, and
+// the text of each hand as split from the file.
+func benchCorpus(tb testing.TB) ([]byte, [][]byte) {
+	tb.Helper()
+	fixtures := []string{
+		cashGame2, multipleWinnersHand, uncalledBetHand, runItTwice, runItTwicePlayerWonBothBoards,
+		allFoldedBeforeFlop, ritEdgeCaseHand,
+	}
+
+	var file bytes.Buffer
+	for i := range benchCorpusHands {
+		if i > 0 {
+			file.WriteString("\n\n\n")
+		}
+		file.WriteString(fixtures[i%len(fixtures)])
+	}
+
+	var handTexts [][]byte
+	scanner := bufio.NewScanner(bytes.NewReader(file.Bytes()))
+	scanner.Split(splitByHands())
+	for scanner.Scan() {
+		handTexts = append(handTexts, bytes.Clone(scanner.Bytes()))
+	}
+	if len(handTexts) != benchCorpusHands {
+		tb.Fatalf("wanted %d hands in the corpus but split %d", benchCorpusHands, len(handTexts))
+	}
+
+	return file.Bytes(), handTexts
+}
+
+func TestParseHandAllocBudget(t *testing.T) {
+	_, handTexts := benchCorpus(t)
+
+	var p handParser
+	allocs := testing.AllocsPerRun(3, func() {
+		for _, h := range handTexts {
+			p.parseHand("bench.txt", h, filePos{})
+		}
+	}) / float64(len(handTexts))
+
+	if allocs > parseAllocBudget {
+		t.Errorf("wanted at most %d allocations per hand but got %.1f", parseAllocBudget, allocs)
+	}
+}
+
+func TestScanHandLinesAllocBudget(t *testing.T) {
+	_, handTexts := benchCorpus(t)
+
+	var p handParser
+	allocs := testing.AllocsPerRun(3, func() {
+		for _, h := range handTexts {
+			p.reset()
+			p.scanHandLines(h)
+		}
+	}) / float64(len(handTexts))
+
+	if allocs > scanAllocBudget {
+		t.Errorf("wanted at most %d allocations per hand but got %.1f", scanAllocBudget, allocs)
+	}
+}
+
+// reportHandRate reports the number of hands processed per second, given the hands processed by each iteration.
+func reportHandRate(b *testing.B, handsPerOp int) {
+	b.ReportMetric(float64(handsPerOp*b.N)/b.Elapsed().Seconds(), "hands/s")
+}
+
+func BenchmarkSplitByHands(b *testing.B) {
+	file, _ := benchCorpus(b)
+	b.SetBytes(int64(len(file)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		scanner := bufio.NewScanner(bytes.NewReader(file))
+		scanner.Split(splitByHands())
+		for scanner.Scan() {
+		}
+	}
+	reportHandRate(b, benchCorpusHands)
+}
+
+func BenchmarkScanHandLines(b *testing.B) {
+	file, handTexts := benchCorpus(b)
+	b.SetBytes(int64(len(file)))
+	b.ReportAllocs()
+
+	var p handParser
+	for b.Loop() {
+		for _, h := range handTexts {
+			p.reset()
+			p.scanHandLines(h)
+		}
+	}
+	reportHandRate(b, benchCorpusHands)
+}
+
+func BenchmarkParseHand(b *testing.B) {
+	file, handTexts := benchCorpus(b)
+	b.SetBytes(int64(len(file)))
+	b.ReportAllocs()
+
+	var p handParser
+	for b.Loop() {
+		for _, h := range handTexts {
+			p.parseHand("bench.txt", h, filePos{})
+		}
+	}
+	reportHandRate(b, benchCorpusHands)
+}
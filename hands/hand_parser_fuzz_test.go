@@ -0,0 +1,135 @@
+package hands
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"math"
+	"os"
+	"testing"
+)
+
+// fuzzSeeds returns the hand histories used by the parser tests, along with the play money example at the root of
+// the repository, as seeds for the fuzz targets.
+func fuzzSeeds(f *testing.F) [][]byte {
+	f.Helper()
+	seeds := [][]byte{}
+	for _, s := range []string{
+		testHands, brokenHands, cashGame2, multipleWinnersHand, handSummary, uncalledBetHand, runItTwice,
+		runItTwicePlayerWonBothBoards, allFoldedBeforeFlop, ritEdgeCaseHand,
+	} {
+		seeds = append(seeds, []byte(s))
+	}
+
+	example, err := os.ReadFile("../example hand history.txt")
+	if err != nil {
+		f.Fatal(err)
+	}
+	return append(seeds, example)
+}
+
+// fuzzLineSeeds returns every line of the fuzz seeds.
+func fuzzLineSeeds(f *testing.F) [][]byte {
+	f.Helper()
+	var lines [][]byte
+	for _, seed := range fuzzSeeds(f) {
+		lines = append(lines, bytes.Split(seed, newLine)...)
+	}
+	return lines
+}
+
+func FuzzParseHands(f *testing.F) {
+	for _, seed := range fuzzSeeds(f) {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		handCh := make(chan handImport)
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+			for h := range handCh {
+				if h.handErr == nil {
+					if h.hand.Metadata.ID == "" {
+						t.Errorf("wanted a hand ID for a hand without an error but got %#v", h.hand)
+					}
+					continue
+				}
+
+				var parseErr *ParseError
+				if !errors.As(h.handErr, &parseErr) {
+					t.Errorf("wanted a *ParseError but got %#v", h.handErr)
+					continue
+				}
+				if parseErr.Line < 1 || parseErr.Offset < 0 || parseErr.Offset > int64(len(data)) {
+					t.Errorf("wanted a position within the input but got line %d offset %d", parseErr.Line, parseErr.Offset)
+				}
+			}
+		}()
+
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanHands("fuzz.txt", scanner, encodingUTF8, filePos{}, false, handCh)
+		close(handCh)
+		<-done
+	})
+}
+
+func FuzzParseActionLine(f *testing.F) {
+	for _, line := range fuzzLineSeeds(f) {
+		f.Add(line)
+	}
+
+	f.Fuzz(func(t *testing.T, line []byte) {
+		p := handParser{street: Preflop}
+
+		action, found, err := p.parseActionLine(line)
+		if err != nil && !errors.Is(err, ErrFailToParseAction) {
+			t.Errorf("wanted an ErrFailToParseAction but got %v", err)
+		}
+
+		if !found || err != nil {
+			if p.order != 0 {
+				t.Errorf("wanted the order unchanged without an action but got %d", p.order)
+			}
+			return
+		}
+
+		if p.order != 1 || action.Order != 1 {
+			t.Errorf("wanted the action to be first but got order %d", action.Order)
+		}
+		if action.Amount < 0 || math.IsNaN(action.Amount) || math.IsInf(action.Amount, 0) {
+			t.Errorf("wanted a finite, non-negative amount but got %v", action.Amount)
+		}
+	})
+}
+
+// FuzzParsePlayer fuzzes each of the lines players are parsed from: seats, hole cards and summary seat results.
+func FuzzParsePlayer(f *testing.F) {
+	for _, line := range fuzzLineSeeds(f) {
+		f.Add(line)
+	}
+
+	parsers := map[string]func(*handParser, []byte) (Player, bool, error){
+		"seat":         (*handParser).parseSeat,
+		"hole cards":   (*handParser).parseHoleCards,
+		"summary seat": (*handParser).parseSummarySeat,
+	}
+
+	f.Fuzz(func(t *testing.T, line []byte) {
+		for name, parse := range parsers {
+			player, found, err := parse(&handParser{}, line)
+			if err != nil && found {
+				t.Errorf("%s: wanted found to be false with an error but got %#v", name, player)
+			}
+
+			if found && !bytes.Contains(line, []byte(player.Username)) {
+				t.Errorf("%s: wanted the username %q to come from the line", name, player.Username)
+			}
+
+			if player.ChipCount < 0 || math.IsNaN(player.ChipCount) || math.IsInf(player.ChipCount, 0) {
+				t.Errorf("%s: wanted a finite, non-negative chip count but got %v", name, player.ChipCount)
+			}
+		}
+	})
+}
@@ -6,7 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"testing/fstest"
@@ -22,10 +25,10 @@ func TestParseHandsAcceptance(t *testing.T) {
 		file, _ := fileSystem.Open("Wei III")
 		scanner := bufio.NewScanner(file)
 		channel := make(chan handImport, 1)
-		ok, scanErr := parseHands("Wei III ", scanner, channel)
+		_, ok, scanErr := scanHands("Wei III ", scanner, encodingUTF8, filePos{}, false, channel)
 
 		if !ok {
-			t.Fatal("wanted ok=true from parseHands but got false")
+			t.Fatal("wanted ok=true from scanHands but got false")
 		}
 		if scanErr != nil {
 			t.Errorf("wanted nil scanErr but got %v", scanErr)
@@ -42,6 +45,9 @@ func TestParseHandsAcceptance(t *testing.T) {
 				ID:         "254446123323",
 				Date:       wantTime,
 				ButtonSeat: 1,
+				Site:       SitePokerStars,
+				Table:      "Wei III",
+				Hero:       "KavarzE",
 			},
 			Players: []Player{{"maximoIV", [2]Card{}, 1, 5.2}, {"dlourencobss", [2]Card{"8s", "9s"}, 2, 4.94}, {"KavarzE", [2]Card{"2s", "5d"}, 3, 5}, {"arsad725", [2]Card{}, 4, 5.49}, {"RE0309", [2]Card{}, 5, 4.63}, {"pernadao1599", [2]Card{"Jh", "Qc"}, 6, 3.43}},
 			Actions: []Action{
@@ -86,10 +92,10 @@ func TestParseHandsAcceptance(t *testing.T) {
 		file, _ := fileSystem.Open("Wei III")
 		scanner := bufio.NewScanner(file)
 		channel := make(chan handImport, 1)
-		ok, scanErr := parseHands("Wei III ", scanner, channel)
+		_, ok, scanErr := scanHands("Wei III ", scanner, encodingUTF8, filePos{}, false, channel)
 
 		if !ok {
-			t.Fatal("wanted ok=true from parseHands but got false")
+			t.Fatal("wanted ok=true from scanHands but got false")
 		}
 		if scanErr != nil {
 			t.Errorf("wanted nil scanErr but got %v", scanErr)
@@ -106,6 +112,9 @@ func TestParseHandsAcceptance(t *testing.T) {
 				ID:         "257507385322",
 				Date:       wantTime,
 				ButtonSeat: 1,
+				Site:       SitePokerStars,
+				Table:      "Halley",
+				Hero:       "KavarzE",
 			},
 			Players: []Player{{"TSCardinals", [2]Card{}, 1, 2.02}, {"Jimmey54", [2]Card{}, 2, 2.21}, {"nm8800", [2]Card{}, 3, 2.31}, {"Chewbacca97", [2]Card{}, 4, 1.08}, {"KavarzE", [2]Card{"8s", "As"}, 5, 2.08}, {"haeorm", [2]Card{}, 6, 6.26}},
 			Actions: []Action{
@@ -148,10 +157,10 @@ func TestParseHandsAcceptance(t *testing.T) {
 		scanner := bufio.NewScanner(file)
 		channel := make(chan handImport, 1)
 
-		ok, _ := parseHands("RIT", scanner, channel)
+		_, ok, _ := scanHands("RIT", scanner, encodingUTF8, filePos{}, false, channel)
 
 		if !ok {
-			t.Fatal("wanted parseHands to be ok=true but got false")
+			t.Fatal("wanted scanHands to be ok=true but got false")
 		}
 
 		etTimeStr := "2025-01-29 11:30:35"
@@ -164,6 +173,9 @@ func TestParseHandsAcceptance(t *testing.T) {
 				ID:         "254607988518",
 				Date:       wantTime.UTC(),
 				ButtonSeat: 1,
+				Site:       SitePokerStars,
+				Table:      "Donati",
+				Hero:       "KavarzE",
 			},
 			Players: []Player{
 				{"TurivVB240492", [2]Card{}, 1, 1.94},
@@ -218,10 +230,10 @@ func TestParseHandsAcceptance(t *testing.T) {
 		file, _ := fileSystem.Open("Halley")
 		scanner := bufio.NewScanner(file)
 		channel := make(chan handImport, 1)
-		ok, scanErr := parseHands("Halley", scanner, channel)
+		_, ok, scanErr := scanHands("Halley", scanner, encodingUTF8, filePos{}, false, channel)
 
 		if !ok {
-			t.Fatal("wanted ok=true from parseHands but got false")
+			t.Fatal("wanted ok=true from scanHands but got false")
 		}
 		if scanErr != nil {
 			t.Errorf("wanted nil scanErr but got %v", scanErr)
@@ -238,6 +250,9 @@ func TestParseHandsAcceptance(t *testing.T) {
 				ID:         "257507021156",
 				Date:       wantTime,
 				ButtonSeat: 1,
+				Site:       SitePokerStars,
+				Table:      "Halley",
+				Hero:       "KavarzE",
 			},
 			Players: []Player{
 				{"KavarzE", [2]Card{"6d", "Th"}, 1, 2},
@@ -289,10 +304,10 @@ func TestParseHandsAcceptance(t *testing.T) {
 		file, _ := fileSystem.Open("Donati")
 		scanner := bufio.NewScanner(file)
 		channel := make(chan handImport, 1)
-		ok, scanErr := parseHands("Donati", scanner, channel)
+		_, ok, scanErr := scanHands("Donati", scanner, encodingUTF8, filePos{}, false, channel)
 
 		if !ok {
-			t.Fatal("wanted ok=true from parseHands but got false")
+			t.Fatal("wanted ok=true from scanHands but got false")
 		}
 		if scanErr != nil {
 			t.Errorf("wanted nil scanErr but got %v", scanErr)
@@ -309,6 +324,9 @@ func TestParseHandsAcceptance(t *testing.T) {
 				ID:         "254449744546",
 				Date:       wantTime,
 				ButtonSeat: 1,
+				Site:       SitePokerStars,
+				Table:      "Donati",
+				Hero:       "KavarzE",
 			},
 			Players: []Player{
 				{"AsmAngAmAngo", [2]Card{}, 1, 6.95},
@@ -367,10 +385,10 @@ func TestParseHandsAcceptance(t *testing.T) {
 		file, _ := fileSystem.Open("Donati")
 		scanner := bufio.NewScanner(file)
 		channel := make(chan handImport, 1)
-		ok, scanErr := parseHands("Donati", scanner, channel)
+		_, ok, scanErr := scanHands("Donati", scanner, encodingUTF8, filePos{}, false, channel)
 
 		if !ok {
-			t.Fatal("wanted ok=true from parseHands but got false")
+			t.Fatal("wanted ok=true from scanHands but got false")
 		}
 		if scanErr != nil {
 			t.Errorf("wanted nil scanErr but got %v", scanErr)
@@ -387,6 +405,9 @@ func TestParseHandsAcceptance(t *testing.T) {
 				ID:         "254626485418",
 				Date:       wantTime,
 				ButtonSeat: 1,
+				Site:       SitePokerStars,
+				Table:      "Donati",
+				Hero:       "KavarzE",
 			},
 			Players: []Player{
 				{"OoJohnStevensoO", [2]Card{}, 1, 6.24},
@@ -426,10 +447,10 @@ func TestParseHandsAcceptance(t *testing.T) {
 		file, _ := fileSystem.Open("Donati")
 		scanner := bufio.NewScanner(file)
 		channel := make(chan handImport, 1)
-		ok, scanErr := parseHands("Donati", scanner, channel)
+		_, ok, scanErr := scanHands("Donati", scanner, encodingUTF8, filePos{}, false, channel)
 
 		if !ok {
-			t.Fatal("wanted ok=true from parseHands but got false")
+			t.Fatal("wanted ok=true from scanHands but got false")
 		}
 		if scanErr != nil {
 			t.Errorf("wanted nil scanErr but got %v", scanErr)
@@ -446,6 +467,9 @@ func TestParseHandsAcceptance(t *testing.T) {
 				ID:         "254626500457",
 				Date:       wantTime,
 				ButtonSeat: 1,
+				Site:       SitePokerStars,
+				Table:      "Donati",
+				Hero:       "KavarzE",
 			},
 			Players: []Player{
 				{"Zutuzutu_90", [2]Card{"Tc", "9c"}, 1, 7.31},
@@ -534,7 +558,11 @@ func TestActionTypeFromText(t *testing.T) {
 func TestParseHandSummary(t *testing.T) {
 	handText := handSummary
 
-	summary, _ := parseHandSummary([]byte(handText))
+	p := handParser{section: sectionSummary, street: Preflop}
+	if err := p.scanHandLines([]byte(handText)); err != nil {
+		t.Fatalf("scanHandLines returned error: %v", err)
+	}
+	summary := p.hand().Summary
 
 	summaryWant := Summary{
 		Pot:  0.36,
@@ -545,7 +573,7 @@ func TestParseHandSummary(t *testing.T) {
 				Card(""),
 			},
 			{}},
-		Winners: []Winner{},
+		Winners: []Winner{{PlayerName: "kv_def", Amount: 0.35, Board: 0}},
 	}
 	if !reflect.DeepEqual(summary, summaryWant) {
 		t.Errorf("got %#v, but wanted %#v", summary, summaryWant)
@@ -564,16 +592,22 @@ func TestParseMetadata(t *testing.T) {
 	handTime, _ := time.ParseInLocation(time.DateTime, rawETTime, loc)
 	wantTime := handTime.UTC()
 
-	metadata, err := parseMetaData([]byte(handText))
+	var p handParser
+	p.reset()
+	err = p.scanHandLines([]byte(handText))
 
 	if err != nil {
-		t.Fatalf("parseMetaData returned error: %v", err)
+		t.Fatalf("scanHandLines returned error: %v", err)
 	}
+	metadata := p.metadata
 
 	metadataWant := Metadata{
 		ID:         "254489598204",
 		Date:       wantTime,
 		ButtonSeat: 1,
+		Site:       SitePokerStars,
+		Table:      "Donati",
+		Hero:       "KavarzE",
 	}
 
 	if metadata != metadataWant {
@@ -581,6 +615,31 @@ func TestParseMetadata(t *testing.T) {
 	}
 }
 
+func TestTableAndHeroFromText(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantTable string
+		wantHero  string
+	}{
+		{"table and hero", "Table 'Wei III' 6-max Seat #1 is the button\nDealt to KavarzE [2s 5d]", "Wei III", "KavarzE"},
+		{"no hero cards dealt", "Table 'Halley' 6-max Seat #1 is the button\nSeat 1: KavarzE ($2 in chips)", "Halley", ""},
+		{"no table line", "Seat 1: KavarzE ($2 in chips)", "", ""},
+		{"unterminated table name", "Table 'Halley", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := string(tableFromText([]byte(tt.text)))
+			hero := string(heroFromText([]byte(tt.text)))
+
+			if table != tt.wantTable || hero != tt.wantHero {
+				t.Errorf("wanted table %q and hero %q but got %q and %q", tt.wantTable, tt.wantHero, table, hero)
+			}
+		})
+	}
+}
+
 func TestPlayerNameActionFromText(t *testing.T) {
 	cases := map[string]string{
 		"kv_def: posts small blind $0.02": "kv_def",
@@ -619,7 +678,7 @@ func TestPlayerNameActionFromText(t *testing.T) {
 	}
 }
 
-func TestActionAmountFromText(t *testing.T) {
+func TestActionAmount(t *testing.T) {
 	t.Run("happy path", func(t *testing.T) {
 		cases := map[string]float64{
 			"kv_def: posts small blind $0.02": 0.02,
@@ -646,9 +705,9 @@ func TestActionAmountFromText(t *testing.T) {
 			fmt.Fprintf(&buffer, "Scenario: %v", c)
 
 			t.Run(buffer.String(), func(t *testing.T) {
-				got, _ := actionAmountFromText([]byte(c))
-				if got != want {
-					t.Errorf("got %v, but wanted %v", got, want)
+				got, found, err := (&handParser{}).parseActionLine([]byte(c))
+				if !found || err != nil || got.Amount != want {
+					t.Errorf("got %v, but wanted %v", got.Amount, want)
 				}
 			})
 		}
@@ -662,9 +721,9 @@ func TestActionAmountFromText(t *testing.T) {
 
 		for c, want := range cases {
 			t.Run(c, func(t *testing.T) {
-				got, _ := actionAmountFromText([]byte(c))
-				if got != want {
-					t.Errorf("got %v, but wanted %v", got, want)
+				got, found, err := (&handParser{}).parseActionLine([]byte(c))
+				if !found || err != nil || got.Amount != want {
+					t.Errorf("got %v, but wanted %v", got.Amount, want)
 				}
 			})
 		}
@@ -674,16 +733,16 @@ func TestActionAmountFromText(t *testing.T) {
 		cases := []string{
 			"kv_def: bets small blind 0.02",
 			"KavarzE: posts big blind 0.05",
-			"KavarzE:  big blind 0.05",
 		}
 
 		for _, c := range cases {
 			t.Run(c, func(t *testing.T) {
-				_, err := actionAmountFromText([]byte(c))
-				want := CurrencyError(fmt.Sprintf("on line %v", c)).Error()
+				_, found, err := (&handParser{}).parseActionLine([]byte(c))
+				_, verb, _ := strings.Cut(c, ": ")
+				want := ActionParseError(CurrencyError(fmt.Sprintf("on line %v", verb)).Error()).Error()
 
-				if err.Error() != want {
-					t.Fatalf("expected \"%v\" error but got \"%v\"", CurrencyError(fmt.Sprintf("on line %v", c)), err)
+				if !found || err == nil || err.Error() != want {
+					t.Fatalf("expected \"%v\" error but got \"%v\"", want, err)
 				}
 			})
 		}
@@ -691,7 +750,7 @@ func TestActionAmountFromText(t *testing.T) {
 	})
 }
 
-func TestExtractHandsFromFile(t *testing.T) {
+func TestExtractHandsFromFileAt(t *testing.T) {
 	t.Run("happy path", func(t *testing.T) {
 		fileSystem := fstest.MapFS{
 			"zoom.txt": {Data: []byte(`PokerStars Hand #123: blah blah
@@ -709,15 +768,15 @@ Seat 1: KavarzE won ($3.80)`)},
 
 		var result bool
 		var fsErr error
-		result, fsErr = extractHandsFromFile(fileSystem, "zoom.txt", handChan)
+		_, result, fsErr = extractHandsFromFileAt(fileSystem, "zoom.txt", filePos{}, false, handChan)
 
 		got := <-handChan
 
 		want := handImport{
-			"zoom.txt",
-			Hand{
-				Metadata{"123", time.Time{}.Local(), 0},
-				[]Player{{
+			filePath: "zoom.txt",
+			hand: Hand{
+				Metadata: Metadata{"123", time.Time{}.Local(), 0, SitePokerStars, "Halley", "KavarzE"},
+				Players: []Player{{
 					Username:  "test",
 					Cards:     [2]Card{"", ""},
 					Seat:      1,
@@ -728,15 +787,13 @@ Seat 1: KavarzE won ($3.80)`)},
 						Seat:      2,
 						ChipCount: 3000},
 				},
-				[]Action{
+				Actions: []Action{
 					{"KavarzE", 1, Preflop, ActionBet, 2.33},
 				},
-				Summary{
+				Summary: Summary{
 					[2]CommunityCards{}, 0, 0, []Winner{},
 				},
 			},
-			nil,
-			false,
 		}
 
 		if got.handErr != nil {
@@ -763,7 +820,7 @@ Seat 1: KavarzE won ($3.80)`)},
 	t.Run("error pathway", func(t *testing.T) {
 		fileSystem := failingFS{}
 		handChan := make(chan handImport, 10000)
-		ok, fsErr := extractHandsFromFile(fileSystem, "zoom.txt", handChan)
+		_, ok, fsErr := extractHandsFromFileAt(fileSystem, "zoom.txt", filePos{}, false, handChan)
 
 		if fsErr == nil {
 			t.Fatal("expected an fsError but didn't get one!")
@@ -797,7 +854,7 @@ Seat 1: KavarzE won ($3.80)`)
 
 		handCh := make(chan handImport, 10000)
 
-		ok, scanErr := parseHands(filename, scanner, handCh)
+		_, ok, scanErr := scanHands(filename, scanner, encodingUTF8, filePos{}, false, handCh)
 
 		got := <-handCh
 
@@ -810,17 +867,15 @@ Seat 1: KavarzE won ($3.80)`)
 		}
 
 		want := handImport{
-			filename,
-			Hand{
-				Metadata{"123", time.Time{}.UTC(), 3},
-				[]Player{
+			filePath: filename,
+			hand: Hand{
+				Metadata: Metadata{"123", time.Time{}.UTC(), 3, SitePokerStars, "Euphemia II", "test"},
+				Players: []Player{
 					{Username: "test", Cards: [2]Card{"Ad", "Ac"}, Seat: 1, ChipCount: 6000},
 					{Username: "test2", Cards: [2]Card{"", ""}, Seat: 2, ChipCount: 3000}},
-				[]Action{{"test", 1, Preflop, ActionBet, 2.33}},
-				Summary{[2]CommunityCards{}, 0.25, 0.01, []Winner{{"KavarzE", 3.80, 1}}},
+				Actions: []Action{{"test", 1, Preflop, ActionBet, 2.33}},
+				Summary: Summary{[2]CommunityCards{}, 0.25, 0.01, []Winner{{"KavarzE", 3.80, 1}}},
 			},
-			nil,
-			false,
 		}
 
 		if !reflect.DeepEqual(got.hand, want.hand) {
@@ -836,7 +891,7 @@ Seat 1: KavarzE won ($3.80)`)
 
 		handChan := make(chan handImport, 10000)
 
-		ok, scanErr := parseHands(filename, scanner, handChan)
+		_, ok, scanErr := scanHands(filename, scanner, encodingUTF8, filePos{}, false, handChan)
 
 		if !ok || scanErr != nil {
 			t.Errorf("wanted non-nil error and ok=true, but got error: %#v, ok: %#v ", scanErr, ok)
@@ -865,7 +920,7 @@ Seat 1: KavarzE won ($3.80)`)
 
 		go func() {
 			defer wg.Done()
-			ok, scanErr = parseHands(filename, scanner, handChan)
+			_, ok, scanErr = scanHands(filename, scanner, encodingUTF8, filePos{}, false, handChan)
 		}()
 
 		wg.Wait()
@@ -919,12 +974,11 @@ func TestHandIdFromText(t *testing.T) {
 }
 
 func TestActionsFromText(t *testing.T) {
-	var dummyStreet = Flop
-	order := 1
+	p := handParser{street: Flop, order: 1}
 
 	handData := []byte(`Kavarz: bets $3`)
 
-	got, _, err := parseActionLine(handData, &dummyStreet, &order)
+	got, _, err := p.parseActionLine(handData)
 	if err != nil {
 		t.Error(err)
 	}
@@ -946,12 +1000,12 @@ func TestDateFromHandText(t *testing.T) {
 		test string
 		want string
 	}{
-		{"PokerStars Zoom Hand #254489598204:  Hold'em No Limit ($0.02/$0.05) - 2025/01/21 20:51:32 WET [2025/01/21 15:51:32 ET]", "2025-01-21 15:51:32"},
-		{"PokerStars Zoom Hand #254489608193:  Hold'em No Limit ($0.02/$0.05) - 2025/01/21 20:52:05 WET [2025/01/21 15:52:05 ET]", "2025-01-21 15:52:05"},
-		{"PokerStars Zoom Hand #254489609065:  Hold'em No Limit ($0.02/$0.05) - 2025/01/21 20:52:09 WET [2025/01/21 15:52:09 ET]", "2025-01-21 15:52:09"},
-		{"PokerStars Zoom Hand #254489686769:  Hold'em No Limit ($0.02/$0.05) - 2025/01/21 20:56:56 WET [2025/01/21 15:56:56 ET]", "2025-01-21 15:56:56"},
-		{"PokerStars Hand #254581458091:  Hold'em No Limit ($0.02/$0.05 USD) - 2025/01/27 17:49:38 WET [2025/01/27 12:49:38 ET]", "2025-01-27 12:49:38"},
-		{"PokerStars Hand #254581458091:  Hold'em No Limit ($0.02/$0.05 USD) - [2025/01/27 12:49:38 ET]", "2025-01-27 12:49:38"},
+		{"PokerStars Zoom Hand #254489598204:  Hold'em No Limit ($0.02/$0.05) - 2025/01/21 20:51:32 WET [2025/01/21 15:51:32 ET]", "2025/01/21 15:51:32"},
+		{"PokerStars Zoom Hand #254489608193:  Hold'em No Limit ($0.02/$0.05) - 2025/01/21 20:52:05 WET [2025/01/21 15:52:05 ET]", "2025/01/21 15:52:05"},
+		{"PokerStars Zoom Hand #254489609065:  Hold'em No Limit ($0.02/$0.05) - 2025/01/21 20:52:09 WET [2025/01/21 15:52:09 ET]", "2025/01/21 15:52:09"},
+		{"PokerStars Zoom Hand #254489686769:  Hold'em No Limit ($0.02/$0.05) - 2025/01/21 20:56:56 WET [2025/01/21 15:56:56 ET]", "2025/01/21 15:56:56"},
+		{"PokerStars Hand #254581458091:  Hold'em No Limit ($0.02/$0.05 USD) - 2025/01/27 17:49:38 WET [2025/01/27 12:49:38 ET]", "2025/01/27 12:49:38"},
+		{"PokerStars Hand #254581458091:  Hold'em No Limit ($0.02/$0.05 USD) - [2025/01/27 12:49:38 ET]", "2025/01/27 12:49:38"},
 		{"PokerStars Hand #254581458091:  Hold'em No Limit ($0.02/$0.05 USD) - ", ""},
 	}
 
@@ -968,20 +1022,41 @@ func TestDateFromHandText(t *testing.T) {
 }
 
 func TestParseDateTime(t *testing.T) {
-	rawETTime := "2025-01-27 12:49:38"
-
 	loc, err := time.LoadLocation("America/New_York")
 	if err != nil {
 		t.Fatalf("failed to load location: %v", err)
 	}
 
-	got := parseDateTime([]byte(rawETTime))
+	cases := []struct {
+		test string
+		want string // in time.DateTime's layout, or empty for the zero time
+	}{
+		{"2025/01/27 12:49:38", "2025-01-27 12:49:38"},
+		{"2025-01-27 12:49:38", "2025-01-27 12:49:38"},
+		{"2025/01/19 7:38:55", "2025-01-19 07:38:55"},
+		{"2025/07/04 23:59:59", "2025-07-04 23:59:59"},
+		{"2025/02/30 12:00:00", ""},
+		{"2025/13/01 12:00:00", ""},
+		{"2025/01/27 24:00:00", ""},
+		{"2025/01/27 12:49", ""},
+		{"2025/01-27 12:49:38", ""},
+		{"", ""},
+	}
 
-	handTime, _ := time.ParseInLocation(time.DateTime, rawETTime, loc)
-	want := handTime.UTC()
+	for _, tt := range cases {
+		t.Run(tt.test, func(t *testing.T) {
+			got := parseDateTime([]byte(tt.test))
+
+			var want time.Time
+			if tt.want != "" {
+				handTime, _ := time.ParseInLocation(time.DateTime, tt.want, loc)
+				want = handTime.UTC()
+			}
 
-	if got != want {
-		t.Errorf("\ngot %v wanted %v", got, want)
+			if got != want {
+				t.Errorf("\ngot %v wanted %v", got, want)
+			}
+		})
 	}
 }
 
@@ -1023,20 +1098,22 @@ func TestCommunityCardsFromText(t *testing.T) {
 }
 
 func TestPlayerCardsFromText(t *testing.T) {
+	summarySeat, holeCards, seat := (*handParser).parseSummarySeat, (*handParser).parseHoleCards, (*handParser).parseSeat
 	cases := []struct {
-		test string
-		want Player
+		test  string
+		parse func(*handParser, []byte) (Player, bool, error)
+		want  Player
 	}{
-		{`Seat 2: KavarzE (small blind) showed [Jc Js] and won ($5.03) with three of a kind, Jacks, and lost with three of a kind, Jacks`, Player{"KavarzE", [2]Card{"Jc", "Js"}, 0, 0}},
-		{`Seat 1: acsy797 (button) mucked [Jd Ks]`, Player{"acsy797", [2]Card{"Jd", "Ks"}, 0, 0}},
-		{`Dealt to KavarzE [Js 5c]`, Player{"KavarzE", [2]Card{"Js", "5c"}, 0, 0}},
-		{`Seat 6: KavarzE ($1.97 in chips) `, Player{"KavarzE", [2]Card{}, 6, 1.97}},
+		{`Seat 2: KavarzE (small blind) showed [Jc Js] and won ($5.03) with three of a kind, Jacks, and lost with three of a kind, Jacks`, summarySeat, Player{"KavarzE", [2]Card{"Jc", "Js"}, 0, 0}},
+		{`Seat 1: acsy797 (button) mucked [Jd Ks]`, summarySeat, Player{"acsy797", [2]Card{"Jd", "Ks"}, 0, 0}},
+		{`Dealt to KavarzE [Js 5c]`, holeCards, Player{"KavarzE", [2]Card{"Js", "5c"}, 0, 0}},
+		{`Seat 6: KavarzE ($1.97 in chips) `, seat, Player{"KavarzE", [2]Card{}, 6, 1.97}},
 	}
 
 	for _, tt := range cases {
 		t.Run(tt.test, func(t *testing.T) {
 
-			got, _, _ := parsePlayer([]byte(tt.test))
+			got, _, _ := tt.parse(&handParser{}, []byte(tt.test))
 
 			if got != tt.want {
 				t.Errorf("got %v but we wanted %v", got, tt.want)
@@ -1124,36 +1201,35 @@ func TestPotFromText(t *testing.T) {
 }
 
 func TestUpdateOrAppendPlayer(t *testing.T) {
-	players := map[string]Player{
-		"KavarzE": {"KavarzE", [2]Card{"", ""}, 1, 6.00},
-		"Javormy": {"Javormy", [2]Card{"", ""}, 2, 33.00},
-		"noob":    {"noob", [2]Card{"", ""}, 3, 4.00},
-	}
+	parser := handParser{players: []Player{
+		{"KavarzE", [2]Card{"", ""}, 1, 6.00},
+		{"Javormy", [2]Card{"", ""}, 2, 33.00},
+		{"noob", [2]Card{"", ""}, 3, 4.00},
+	}}
 
-	updateOrAddPlayer(
-		players,
+	parser.updateOrAddPlayer(
 		Player{"KavarzE", [2]Card{"Ac", "Ad"}, 1, 6.00},
 	)
 
-	if len(players) != 3 {
-		t.Errorf("expected player length of 3 but got %v", len(players))
+	if len(parser.players) != 3 {
+		t.Errorf("expected player length of 3 but got %v", len(parser.players))
 	}
 
-	for _, p := range players {
+	for _, p := range parser.players {
 		if p.Username == "KavarzE" && p.Cards != [2]Card{"Ac", "Ad"} {
 			t.Errorf("wanted updated cards of %v but got %v", [2]Card{"Ac", "Ad"}, p.Cards)
 		}
 	}
 }
 
-func TestConvertToSlice(t *testing.T) {
-	players := map[string]Player{
-		"KavarzE": {"KavarzE", [2]Card{"", ""}, 1, 6.00},
-		"Javormy": {"Javormy", [2]Card{"", ""}, 3, 33.00},
-		"noob":    {"noob", [2]Card{"", ""}, 2, 4.00},
+func TestSortedBySeat(t *testing.T) {
+	players := []Player{
+		{"KavarzE", [2]Card{"", ""}, 1, 6.00},
+		{"Javormy", [2]Card{"", ""}, 3, 33.00},
+		{"noob", [2]Card{"", ""}, 2, 4.00},
 	}
 
-	got := convertToSlice(players)
+	got := sortedBySeat(players)
 
 	if got[1].Username != "noob" {
 		t.Errorf("wanted username: 'noob' in position 1 in slice, but got username of %v", got[1].Username)
@@ -1213,7 +1289,8 @@ func TestNoShowdownWinner(t *testing.T) {
 
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := noShowdownWinner([]byte(tt.line), tt.street)
+			p := handParser{street: tt.street}
+			winner, found, err := p.noShowdownWinner([]byte(tt.line))
 			if tt.wantErr {
 				if err == nil {
 					t.Errorf("expected error, got nil")
@@ -1223,6 +1300,10 @@ func TestNoShowdownWinner(t *testing.T) {
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
+			got := []Winner{}
+			if found {
+				got = append(got, winner)
+			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("got %+v, want %+v", got, tt.want)
 			}
@@ -1250,6 +1331,18 @@ func TestWinnerFromLine(t *testing.T) {
 			boardNum: 2,
 			want:     []Winner{{PlayerName: "ribo7falani", Amount: 5.12, Board: 2}},
 		},
+		{
+			name:     "side pot winner",
+			line:     "Jero0987 collected $3.40 from side pot-2",
+			boardNum: 1,
+			want:     []Winner{{PlayerName: "Jero0987", Amount: 3.4, Board: 1}},
+		},
+		{
+			name:     "main pot winner",
+			line:     "Jero0987 collected $1.02 from main pot",
+			boardNum: 2,
+			want:     []Winner{{PlayerName: "Jero0987", Amount: 1.02, Board: 2}},
+		},
 		{
 			name:     "non-matching line returns empty",
 			line:     "ribo7falani: shows [Kh Jd] (a full house, Jacks full of Kings)",
@@ -1273,7 +1366,7 @@ func TestWinnerFromLine(t *testing.T) {
 
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := winnerFromLine([]byte(tt.line), tt.boardNum)
+			winner, found, err := (&handParser{}).winnerFromLine([]byte(tt.line), tt.boardNum)
 			if tt.wantErr {
 				if err == nil {
 					t.Errorf("expected error, got nil")
@@ -1283,6 +1376,10 @@ func TestWinnerFromLine(t *testing.T) {
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
+			got := []Winner{}
+			if found {
+				got = append(got, winner)
+			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("got %+v, want %+v", got, tt.want)
 			}
@@ -1290,69 +1387,75 @@ func TestWinnerFromLine(t *testing.T) {
 	}
 }
 
-func TestExtractWinners(t *testing.T) {
-
+// TestParseLineWinners covers the winners parsed from the lines of the showdown, or of the summary of a hand without
+// a showdown.
+func TestParseLineWinners(t *testing.T) {
 	cases := []struct {
 		name    string
 		line    string
-		state   ShowdownState
+		section section
+		board   int
 		street  Street
 		want    []Winner
 		wantErr bool
 	}{
 		{
-			name:   "no showdown, preflop fold winner",
-			line:   "Seat 6: KavarzE collected ($0.12)",
-			state:  noShowdown,
-			street: Preflop,
-			want:   []Winner{{PlayerName: "KavarzE", Amount: 0.12, Board: 0}},
+			name:    "no showdown, preflop fold winner",
+			line:    "Seat 6: KavarzE collected ($0.12)",
+			section: sectionSummary,
+			street:  Preflop,
+			want:    []Winner{{PlayerName: "KavarzE", Amount: 0.12, Board: 0}},
 		},
 		{
-			name:   "no showdown, postflop winner board 1",
-			line:   "Seat 1: KavarzE (button) collected ($0.27)",
-			state:  noShowdown,
-			street: Turn,
-			want:   []Winner{{PlayerName: "KavarzE", Amount: 0.27, Board: 1}},
+			name:    "no showdown, postflop winner board 1",
+			line:    "Seat 1: KavarzE (button) collected ($0.27)",
+			section: sectionSummary,
+			street:  Turn,
+			want:    []Winner{{PlayerName: "KavarzE", Amount: 0.27, Board: 1}},
 		},
 		{
-			name:   "no showdown, non-winner summary line ignored",
-			line:   "Seat 2: SpieWNogach (small blind) folded before Flop",
-			state:  noShowdown,
-			street: Preflop,
-			want:   []Winner{},
+			name:    "no showdown, non-winner summary line ignored",
+			line:    "Seat 2: SpieWNogach (small blind) folded before Flop",
+			section: sectionSummary,
+			street:  Preflop,
+			want:    []Winner{},
 		},
 		{
-			name:   "rio winner",
-			line:   "Jero0987 collected $5.12 from pot",
-			state:  rio,
-			street: River,
-			want:   []Winner{{PlayerName: "Jero0987", Amount: 5.12, Board: 1}},
+			name:    "rio winner",
+			line:    "Jero0987 collected $5.12 from pot",
+			section: sectionShowdown,
+			board:   1,
+			street:  River,
+			want:    []Winner{{PlayerName: "Jero0987", Amount: 5.12, Board: 1}},
 		},
 		{
-			name:   "rit first board winner",
-			line:   "Jero0987 collected $5.12 from pot",
-			state:  ritFirstBoard,
-			street: River,
-			want:   []Winner{{PlayerName: "Jero0987", Amount: 5.12, Board: 1}},
+			name:    "rit first board winner",
+			line:    "Jero0987 collected $5.12 from pot",
+			section: sectionShowdown,
+			board:   1,
+			street:  River,
+			want:    []Winner{{PlayerName: "Jero0987", Amount: 5.12, Board: 1}},
 		},
 		{
-			name:   "rit second board winner",
-			line:   "ribo7falani collected $5.12 from pot",
-			state:  ritSecondBoard,
-			street: River,
-			want:   []Winner{{PlayerName: "ribo7falani", Amount: 5.12, Board: 2}},
+			name:    "rit second board winner",
+			line:    "ribo7falani collected $5.12 from pot",
+			section: sectionShowdown,
+			board:   2,
+			street:  River,
+			want:    []Winner{{PlayerName: "ribo7falani", Amount: 5.12, Board: 2}},
 		},
 		{
-			name:   "non-winner line in showdown ignored",
-			line:   "ribo7falani: shows [Kh Jd] (a full house, Jacks full of Kings)",
-			state:  rio,
-			street: River,
-			want:   []Winner{},
+			name:    "non-winner line in showdown ignored",
+			line:    "ribo7falani: shows [Kh Jd] (a full house, Jacks full of Kings)",
+			section: sectionShowdown,
+			board:   1,
+			street:  River,
+			want:    []Winner{},
 		},
 		{
 			name:    "invalid amount returns error",
 			line:    "Seat 1: KavarzE (button) collected (£0.27)",
-			state:   noShowdown,
+			section: sectionSummary,
 			street:  Turn,
 			want:    []Winner{},
 			wantErr: true,
@@ -1361,16 +1464,20 @@ func TestExtractWinners(t *testing.T) {
 
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := extractWinners([]byte(tt.line), tt.state, tt.street)
+			p := handParser{section: tt.section, board: tt.board, street: tt.street}
+			if err := p.parseLine([]byte(tt.line)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 			if tt.wantErr {
-				if err == nil {
-					t.Errorf("expected error, got nil")
+				if len(p.warnings) == 0 || p.warnings[0].Kind != KindWinner {
+					t.Errorf("expected a winner warning, got %v", p.warnings)
 				}
 				return
 			}
-			if err != nil {
-				t.Fatalf("unexpected error: %v", err)
+			if len(p.warnings) != 0 {
+				t.Fatalf("unexpected warnings: %v", p.warnings)
 			}
+			got := p.hand().Summary.Winners
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("got %+v, want %+v", got, tt.want)
 			}
@@ -1378,6 +1485,28 @@ func TestExtractWinners(t *testing.T) {
 	}
 }
 
+// TestParseSidePotShowdown covers a showdown where a short stack all-in wins the main pot and the side pot it could
+// not contest goes to another player.
+func TestParseSidePotShowdown(t *testing.T) {
+	h := parseText(t, []byte(sidePotShowdownHand))[0]
+
+	if len(h.Warnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", h.Warnings)
+	}
+
+	want := []Winner{
+		{PlayerName: "bigStack", Amount: 3.85, Board: 1},
+		{PlayerName: "shortie", Amount: 2.85, Board: 1},
+	}
+	if !reflect.DeepEqual(h.Summary.Winners, want) {
+		t.Errorf("wanted %+v but got %+v", want, h.Summary.Winners)
+	}
+
+	if h.Summary.Pot != 7 || h.Summary.Rake != 0.3 {
+		t.Errorf("wanted a $7 pot and $0.30 rake but got %v and %v", h.Summary.Pot, h.Summary.Rake)
+	}
+}
+
 func TestExtractButtonSeatFromText(t *testing.T) {
 	cases := []struct {
 		test string
@@ -1436,6 +1565,18 @@ Seat 1: TSCardinals ($2.02 in chips)
 			end:   " is the button",
 			want:  "1",
 		},
+		{
+			test:  "Dealt to KavarzE [Ac Dc",
+			start: " [",
+			end:   "]",
+			want:  "",
+		},
+		{
+			test:  "Dealt to KavarzE",
+			start: " [",
+			end:   "]",
+			want:  "",
+		},
 	}
 
 	for _, tt := range cases {
@@ -1447,6 +1588,234 @@ Seat 1: TSCardinals ($2.02 in chips)
 	}
 }
 
+// TestParseMalformedLines covers lines found by fuzzing that used to panic the parser.
+func TestParseMalformedLines(t *testing.T) {
+	t.Run("action", func(t *testing.T) {
+		var p handParser
+		for _, line := range []string{"KavarzE calls", "KavarzE:x calls $0.05"} {
+			if _, _, err := p.parseActionLine([]byte(line)); !errors.Is(err, ErrFailToParseAction) {
+				t.Errorf("wanted ErrFailToParseAction for %q but got %v", line, err)
+			}
+		}
+	})
+
+	t.Run("player", func(t *testing.T) {
+		var p handParser
+		if _, found, err := p.parseSeat([]byte("Seat 1: adevlupec (53368 in chips) ")); found || err == nil {
+			t.Errorf("wanted an error for play money chips but got found %v and %v", found, err)
+		}
+	})
+
+	t.Run("hand ID", func(t *testing.T) {
+		var p handParser
+		if err := p.parseHeader([]byte("PokerStars Hand #: Hold'em No Limit")); !errors.Is(err, ErrNoHandID) {
+			t.Errorf("wanted ErrNoHandID for an empty hand number but got %v", err)
+		}
+	})
+}
+
+func TestScanHandLinesNoButton(t *testing.T) {
+	cases := map[string]string{
+		"no table line":        "PokerStars Hand #1: Hold'em No Limit ($0.02/$0.05)\nSeat 1: KavarzE ($2 in chips)",
+		"no button":            "PokerStars Hand #1: Hold'em No Limit ($0.02/$0.05)\nTable 'Halley' 6-max\nSeat 1: KavarzE ($2 in chips)",
+		"only the header line": "PokerStars Hand #1: Hold'em No Limit ($0.02/$0.05)",
+	}
+
+	for name, handText := range cases {
+		t.Run(name, func(t *testing.T) {
+			var p handParser
+			p.reset()
+			err := p.scanHandLines([]byte(handText))
+
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) || !errors.Is(err, ErrNoButton) || parseErr.Kind != KindMetadata || parseErr.Line != 1 {
+				t.Errorf("wanted a metadata error for the header line but got %#v", err)
+			}
+		})
+	}
+}
+
+// extractAll extracts the hands in name from position start, returning them and the position extracting ended at.
+func extractAll(t *testing.T, fileSystem fs.FS, name string, start filePos) ([]handImport, filePos) {
+	t.Helper()
+	handChan := make(chan handImport)
+	done := make(chan []handImport)
+	go func() {
+		var got []handImport
+		for h := range handChan {
+			got = append(got, h)
+		}
+		done <- got
+	}()
+
+	end, ok, err := extractHandsFromFileAt(fileSystem, name, start, false, handChan)
+	close(handChan)
+	got := <-done
+
+	if !ok || err != nil {
+		t.Fatalf("wanted the file to be extracted but got %v and %v", ok, err)
+	}
+	return got, end
+}
+
+func TestExtractHandsFromLargeFile(t *testing.T) {
+	tooLarge := "PokerStars Hand #1: Hold'em No Limit ($0.02/$0.05)\n" +
+		strings.Repeat("Seat 1: KavarzE ($2 in chips)\n", maxHandSize/30+1)
+
+	cases := []struct {
+		name    string
+		data    string
+		wantIDs []string // the hands wanted after the hand that is too large
+	}{
+		{"between hands", cashGame2 + "\n\n\n" + tooLarge + "\n\n" + uncalledBetHand, []string{"257507385322"}},
+		{"at the end", cashGame2 + "\n\n\n" + tooLarge, nil},
+		{"without a line break", cashGame2 + "\n\n\nPokerStars Hand #1: " + strings.Repeat("x", 3*maxHandSize) + "\n" + uncalledBetHand, []string{"257507385322"}},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(tt.data)
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "large.txt"), data, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			// the file on disk is mapped into memory, the file in memory is read as a stream
+			mapped, mappedEnd := extractAll(t, os.DirFS(dir), "large.txt", filePos{})
+			streamed, streamedEnd := extractAll(t, fstest.MapFS{"large.txt": {Data: data}}, "large.txt", filePos{})
+
+			if !reflect.DeepEqual(mapped, streamed) || mappedEnd != streamedEnd {
+				t.Fatalf("wanted the same hands mapped and streamed but got %d ending at %v and %d ending at %v",
+					len(mapped), mappedEnd, len(streamed), streamedEnd)
+			}
+			if mappedEnd.offset != int64(len(data)) {
+				t.Errorf("wanted the whole file extracted but ended at %d of %d", mappedEnd.offset, len(data))
+			}
+
+			if len(mapped) != 2+len(tt.wantIDs) {
+				t.Fatalf("wanted %d hands but got %d", 2+len(tt.wantIDs), len(mapped))
+			}
+			if mapped[0].handErr != nil {
+				t.Errorf("wanted the hand before the one too large to parse but got %v", mapped[0].handErr)
+			}
+
+			var parseErr *ParseError
+			if !errors.As(mapped[1].handErr, &parseErr) || parseErr.Kind != KindTooLarge || !errors.Is(parseErr, ErrHandTooLarge) {
+				t.Fatalf("wanted the hand to be too large but got %v", mapped[1].handErr)
+			}
+			if start := int64(len(cashGame2) + 3); parseErr.Offset != start || parseErr.Line != bytes.Count(data[:start], newLine)+1 {
+				t.Errorf("wanted the error at the start of the hand but got line %d offset %d", parseErr.Line, parseErr.Offset)
+			}
+			// the hand keeps the start of its first line, which the delimiter before it is split from
+			if len(mapped[1].handText) > maxHandSize+len(handDelimiter)-1 || !bytes.HasPrefix(data[parseErr.Offset:], mapped[1].handText) {
+				t.Errorf("wanted the start of the hand kept for the quarantine but got %d bytes", len(mapped[1].handText))
+			}
+
+			for i, id := range tt.wantIDs {
+				if h := mapped[2+i]; h.handErr != nil || h.hand.Metadata.ID != id {
+					t.Errorf("wanted hand %s after the hand too large but got %q and %v", id, h.hand.Metadata.ID, h.handErr)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractHandsFromFileAtMapped(t *testing.T) {
+	data := []byte(testHands + "\n\n\n" + cashGame2)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "zoom.txt"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// resume from the end of the first hand, as an incremental import does
+	first, _, _ := bytes.Cut(data, handDelimiter)
+	start := filePos{offset: int64(len(first) + len(handDelimiter)), lines: bytes.Count(first, newLine) + 1}
+
+	mapped, mappedEnd := extractAll(t, os.DirFS(dir), "zoom.txt", start)
+	streamed, streamedEnd := extractAll(t, fstest.MapFS{"zoom.txt": {Data: data}}, "zoom.txt", start)
+
+	if len(mapped) != 1 || mapped[0].hand.Metadata.ID != "254446123323" || !reflect.DeepEqual(mapped, streamed) ||
+		mappedEnd != streamedEnd {
+		t.Errorf("wanted the same last hand mapped and streamed but got %#v and %#v", mapped, streamed)
+	}
+
+	past, pastEnd := extractAll(t, os.DirFS(dir), "zoom.txt", filePos{offset: int64(len(data)) + 10})
+	if len(past) != 0 || pastEnd.offset != int64(len(data))+10 {
+		t.Errorf("wanted no hands past the end of the file but got %d ending at %v", len(past), pastEnd)
+	}
+}
+
+func TestExtractHandsFromEncodedFile(t *testing.T) {
+	// usernames with non-ASCII characters, one of them outside the Basic Multilingual Plane
+	brokenHand := "PokerStars Hand #254446123324:  Hold'em No Limit ($0.02/$0.05 USD) - 2025/01/19 12:39:55 WET [2025/01/19 7:39:55 ET]\n" +
+		"Table 'Wei III' 6-max Seat #1 is the button\n" +
+		"Seat 1: Jürgen ($5.20 in chips)\n"
+	text := strings.ReplaceAll(cashGame2, "pernadao1599", "pernadão🂡") + "\n\n\n" + brokenHand + "\n\n\n" + uncalledBetHand
+
+	want, _ := extractAll(t, fstest.MapFS{"hh.txt": {Data: []byte(text)}}, "hh.txt", filePos{})
+	if len(want) != 3 || want[0].handErr != nil || want[0].hand.Players[5].Username != "pernadão🂡" || want[1].handErr == nil {
+		t.Fatalf("wanted the UTF-8 hands parsed but got %#v", want)
+	}
+
+	cases := []struct {
+		name string
+		enc  fileEncoding
+		bom  bool
+	}{
+		{"UTF-8 with a byte order mark", encodingUTF8, true},
+		{"UTF-16LE with a byte order mark", encodingUTF16LE, true},
+		{"UTF-16LE", encodingUTF16LE, false},
+		{"UTF-16BE with a byte order mark", encodingUTF16BE, true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			data := encodeText(text, tt.enc, tt.bom)
+			_, bomLen := detectEncoding(data)
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "hh.txt"), data, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			// positions are in the bytes of the file, however it is encoded
+			fileOffset := func(i int) int64 {
+				return int64(bomLen) + tt.enc.size([]byte(text[:i]))
+			}
+			brokenPos := filePos{offset: fileOffset(strings.Index(text, brokenHand)), lines: strings.Count(cashGame2, "\n") + 3}
+
+			for _, fileSystem := range []fs.FS{os.DirFS(dir), fstest.MapFS{"hh.txt": {Data: data}}} {
+				got, end := extractAll(t, fileSystem, "hh.txt", filePos{})
+
+				if end.offset != int64(len(data)) || end.lines != strings.Count(text, "\n") {
+					t.Errorf("wanted the whole file extracted but ended at %v of %d", end, len(data))
+				}
+				if len(got) != len(want) {
+					t.Fatalf("wanted %d hands but got %d", len(want), len(got))
+				}
+
+				for i := range want {
+					if !reflect.DeepEqual(got[i].hand, want[i].hand) || !bytes.Equal(got[i].handText, want[i].handText) {
+						t.Errorf("wanted hand %d parsed as it is from UTF-8 but got %#v", i, got[i].hand)
+					}
+				}
+
+				var parseErr *ParseError
+				if !errors.As(got[1].handErr, &parseErr) || parseErr.Offset != brokenPos.offset || parseErr.Line != brokenPos.lines+1 ||
+					got[1].handStart != brokenPos {
+					t.Errorf("wanted the broken hand at %v but got %v starting at %v", brokenPos, got[1].handErr, got[1].handStart)
+				}
+
+				// resume after the first hand, as an incremental import does
+				first := strings.Index(text, string(handDelimiter)) + len(handDelimiter)
+				resumed, resumedEnd := extractAll(t, fileSystem, "hh.txt", filePos{offset: fileOffset(first), lines: strings.Count(text[:first], "\n")})
+				if len(resumed) != 2 || resumed[1].hand.Metadata.ID != "257507385322" || resumedEnd != end {
+					t.Errorf("wanted the last 2 hands when resuming but got %d ending at %v", len(resumed), resumedEnd)
+				}
+			}
+		})
+	}
+}
+
 type failingFS struct{}
 
 func (f failingFS) Open(_ string) (fs.File, error) {
@@ -1934,3 +2303,30 @@ Seat 3: darchas (big blind) folded before Flop
 Seat 4: soyjuliansito folded before Flop (didn't bet)
 Seat 5: SpieWNogach folded before Flop (didn't bet)
 Seat 6: Trogloditapubg folded before Flop (didn't bet)`
+
+const sidePotShowdownHand string = `PokerStars Hand #254446123399:  Hold'em No Limit ($0.02/$0.05 USD) - 2025/01/19 12:40:12 WET [2025/01/19 7:40:12 ET]
+Table 'Wei III' 6-max Seat #1 is the button
+Seat 1: shortie ($1 in chips)
+Seat 2: midStack ($3 in chips)
+Seat 3: bigStack ($10 in chips)
+midStack: posts small blind $0.02
+bigStack: posts big blind $0.05
+*** HOLE CARDS ***
+shortie: raises $0.95 to $1 and is all-in
+midStack: raises $2 to $3 and is all-in
+bigStack: calls $2.95
+*** FLOP *** [2h Ts Jc]
+*** TURN *** [2h Ts Jc] [3h]
+*** RIVER *** [2h Ts Jc 3h] [8c]
+*** SHOW DOWN ***
+shortie: shows [Jh Jd] (three of a kind, Jacks)
+midStack: shows [As Kd] (high card Ace)
+bigStack: shows [Th 9h] (a pair of Tens)
+bigStack collected $3.85 from side pot
+shortie collected $2.85 from main pot
+*** SUMMARY ***
+Total pot $7 Main pot $2.85. Side pot $3.85. | Rake $0.30
+Board [2h Ts Jc 3h 8c]
+Seat 1: shortie (button) showed [Jh Jd] and won ($2.85) with three of a kind, Jacks
+Seat 2: midStack (small blind) showed [As Kd] and lost with high card Ace
+Seat 3: bigStack (big blind) showed [Th 9h] and won ($3.85) with a pair of Tens`
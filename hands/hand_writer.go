@@ -0,0 +1,370 @@
+package hands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// HandSeparator is written between hands, matching the blank lines PokerStars leaves between hands in a file
+const HandSeparator = "\n\n\n"
+
+// SiteTimeLayout is the layout of the times in the first line of a PokerStars hand
+const SiteTimeLayout = "2006/01/02 15:04:05"
+
+// HandWriter writes hands as PokerStars hand history text that can be parsed again, e.g. once they have been
+// filtered or anonymised.
+type HandWriter struct {
+	w     io.Writer
+	count int
+}
+
+// NewHandWriter returns a HandWriter that writes to w.
+func NewHandWriter(w io.Writer) *HandWriter {
+	return &HandWriter{w: w}
+}
+
+// Write renders h and writes it, separated from the previous hand by blank lines.
+func (hw *HandWriter) Write(h Hand) error {
+	if hw.count > 0 {
+		if _, err := io.WriteString(hw.w, HandSeparator); err != nil {
+			return err
+		}
+	}
+	hw.count++
+
+	_, err := hw.w.Write(RenderHand(h))
+	return err
+}
+
+// RenderHand renders h as PokerStars hand history text. Only the details captured by the parser are rendered, so
+// lines such as uncalled bets being returned or the hand each player made at showdown are left out.
+func RenderHand(h Hand) []byte {
+	r := handRenderer{hand: h, folded: map[string]Street{}, acted: map[string]bool{}}
+	r.header()
+	r.actions()
+	r.showdown()
+	r.summary()
+	return r.buf.Bytes()
+}
+
+type handRenderer struct {
+	buf    bytes.Buffer
+	hand   Hand
+	folded map[string]Street
+	acted  map[string]bool
+}
+
+func (r *handRenderer) line(format string, args ...any) {
+	fmt.Fprintf(&r.buf, format, args...)
+	r.buf.WriteByte('\n')
+}
+
+func (r *handRenderer) header() {
+	h := r.hand
+	small, big := h.Blinds()
+
+	r.line("PokerStars Hand #%s:  Hold'em No Limit (%s/%s %s) - %s UTC [%s ET]",
+		h.Metadata.ID,
+		renderAmount(small),
+		renderAmount(big),
+		CurrencyUSD,
+		h.Metadata.Date.UTC().Format(SiteTimeLayout),
+		h.Metadata.Date.In(siteLocation).Format(SiteTimeLayout),
+	)
+
+	maxSeat := 0
+	for _, p := range h.Players {
+		maxSeat = max(maxSeat, p.Seat)
+	}
+	r.line("Table '%s' %d-max Seat #%d is the button", h.Metadata.Table, tableSize(maxSeat), h.Metadata.ButtonSeat)
+
+	for _, p := range h.Players {
+		r.line("Seat %d: %s (%s in chips)", p.Seat, p.Username, renderAmount(p.ChipCount))
+	}
+}
+
+// tableSize returns the smallest common table size seating maxSeat players
+func tableSize(maxSeat int) int {
+	for _, size := range []int{2, 6, 9} {
+		if maxSeat <= size {
+			return size
+		}
+	}
+	return maxSeat
+}
+
+func (r *handRenderer) actions() {
+	h := r.hand
+	_, big := h.Blinds()
+	board := h.Summary.CommunityCards[0]
+	bets := NewStreetBets()
+	street := Preflop
+	holeCardsDealt := false
+
+	dealHoleCards := func() {
+		holeCardsDealt = true
+		r.line("*** HOLE CARDS ***")
+		for _, p := range h.Players {
+			if p.Username == h.Metadata.Hero && p.Cards[0] != "" {
+				r.line("Dealt to %s [%s %s]", p.Username, p.Cards[0], p.Cards[1])
+			}
+		}
+	}
+
+	for _, a := range h.Actions {
+		if a.ActionType != ActionPost && !holeCardsDealt {
+			dealHoleCards()
+		}
+
+		for street != a.Street && street != River {
+			street = nextStreet(street)
+			r.dealStreet("", street, board)
+		}
+
+		r.acted[a.PlayerName] = true
+		switch a.ActionType {
+		case ActionPost:
+			blind := "big blind"
+			if a.Amount < big {
+				blind = "small blind"
+			}
+			r.line("%s: posts %s %s", a.PlayerName, blind, renderAmount(a.Amount))
+			bets.Add(a.Street, a.PlayerName, a.Amount)
+		case ActionFold:
+			r.line("%s: folds", a.PlayerName)
+			r.folded[a.PlayerName] = a.Street
+		case ActionCheck:
+			r.line("%s: checks", a.PlayerName)
+		case ActionCall, ActionBet:
+			r.line("%s: %ss %s", a.PlayerName, a.ActionType, renderAmount(a.Amount))
+			bets.Add(a.Street, a.PlayerName, a.Amount)
+		case ActionRaise:
+			to := bets.Raise(a.Street, a.PlayerName, a.Amount)
+			r.line("%s: raises %s to %s", a.PlayerName, renderAmount(a.Amount), renderAmount(to))
+		}
+	}
+
+	if !holeCardsDealt {
+		dealHoleCards()
+	}
+
+	// streets dealt once the betting is over, e.g. because every player is all-in
+	second := h.Summary.CommunityCards[1]
+	if second.River == "" {
+		for street != River && boardDealt(board, nextStreet(street)) {
+			street = nextStreet(street)
+			r.dealStreet("", street, board)
+		}
+		return
+	}
+
+	// the second run starts from the first street its cards differ from the first board
+	runFrom := River
+	switch {
+	case board.Flop != second.Flop:
+		runFrom = Flop
+	case board.Turn != second.Turn:
+		runFrom = Turn
+	}
+
+	for street != River && nextStreet(street) != runFrom {
+		street = nextStreet(street)
+		r.dealStreet("", street, board)
+	}
+	for s := runFrom; ; s = nextStreet(s) {
+		r.dealStreet("FIRST ", s, board)
+		if s == River {
+			break
+		}
+	}
+	for s := runFrom; ; s = nextStreet(s) {
+		r.dealStreet("SECOND ", s, second)
+		if s == River {
+			break
+		}
+	}
+}
+
+func nextStreet(s Street) Street {
+	switch s {
+	case Preflop:
+		return Flop
+	case Flop:
+		return Turn
+	default:
+		return River
+	}
+}
+
+func boardDealt(board CommunityCards, s Street) bool {
+	switch s {
+	case Flop:
+		return board.Flop[0] != ""
+	case Turn:
+		return board.Turn != ""
+	case River:
+		return board.River != ""
+	default:
+		return false
+	}
+}
+
+// dealStreet writes the line dealing the cards of street, e.g. "*** TURN *** [Qc As 3d] [2h]"
+func (r *handRenderer) dealStreet(run string, street Street, board CommunityCards) {
+	flop := fmt.Sprintf("%s %s %s", board.Flop[0], board.Flop[1], board.Flop[2])
+	switch street {
+	case Flop:
+		r.line("*** %sFLOP *** [%s]", run, flop)
+	case Turn:
+		r.line("*** %sTURN *** [%s] [%s]", run, flop, board.Turn)
+	case River:
+		r.line("*** %sRIVER *** [%s %s] [%s]", run, flop, board.Turn, board.River)
+	}
+}
+
+// wentToShowdown reports whether more than one player was left in the hand once the betting was over
+func (r *handRenderer) wentToShowdown() bool {
+	for _, w := range r.hand.Summary.Winners {
+		if w.Board == 0 {
+			return false
+		}
+	}
+
+	remaining := 0
+	for name := range r.acted {
+		if _, ok := r.folded[name]; !ok {
+			remaining++
+		}
+	}
+	return remaining > 1
+}
+
+func (r *handRenderer) runTwice() bool {
+	return r.hand.Summary.CommunityCards[1].River != ""
+}
+
+func (r *handRenderer) showdown() {
+	if !r.wentToShowdown() {
+		return
+	}
+
+	boards := []int{1}
+	if r.runTwice() {
+		boards = []int{1, 2}
+	}
+
+	for _, board := range boards {
+		switch {
+		case !r.runTwice():
+			r.line("*** SHOW DOWN ***")
+		case board == 1:
+			r.line("*** FIRST SHOW DOWN ***")
+		default:
+			r.line("*** SECOND SHOW DOWN ***")
+		}
+
+		for _, p := range r.hand.Players {
+			if _, folded := r.folded[p.Username]; r.acted[p.Username] && !folded && p.Cards[0] != "" {
+				r.line("%s: shows [%s %s]", p.Username, p.Cards[0], p.Cards[1])
+			}
+		}
+
+		for _, w := range r.hand.Summary.Winners {
+			if w.Board == board {
+				r.line("%s collected %s from pot", w.PlayerName, renderAmount(w.Amount))
+			}
+		}
+	}
+}
+
+func (r *handRenderer) summary() {
+	h := r.hand
+	r.line("*** SUMMARY ***")
+	r.line("Total pot %s | Rake %s", renderAmount(h.Summary.Pot), renderAmount(h.Summary.Rake))
+
+	first, second := boardCards(h.Summary.CommunityCards[0]), boardCards(h.Summary.CommunityCards[1])
+	switch {
+	case r.runTwice():
+		r.line("Hand was run twice")
+		r.line("FIRST Board [%s]", first)
+		r.line("SECOND Board [%s]", second)
+	case first != "":
+		r.line("Board [%s]", first)
+	}
+
+	showdown := r.wentToShowdown()
+	for _, p := range h.Players {
+		var seat bytes.Buffer
+		fmt.Fprintf(&seat, "Seat %d: %s", p.Seat, p.Username)
+		if p.Seat == h.Metadata.ButtonSeat {
+			seat.WriteString(" (button)")
+		}
+
+		var won float64
+		for _, w := range h.Summary.Winners {
+			if w.PlayerName == p.Username {
+				won += w.Amount
+			}
+		}
+
+		street, folded := r.folded[p.Username]
+		switch {
+		case p.Cards[0] != "" && (p.Username != h.Metadata.Hero || showdown && !folded):
+			fmt.Fprintf(&seat, " showed [%s %s]", p.Cards[0], p.Cards[1])
+			if won > 0 {
+				fmt.Fprintf(&seat, " and won (%s)", renderAmount(won))
+			} else {
+				seat.WriteString(" and lost")
+			}
+		case folded && street == Preflop:
+			seat.WriteString(" folded before Flop")
+		case folded:
+			fmt.Fprintf(&seat, " folded on the %s", streetTitle(street))
+		case !showdown && won > 0:
+			fmt.Fprintf(&seat, " collected (%s)", renderAmount(won))
+		case r.acted[p.Username]:
+			seat.WriteString(" mucked")
+		default:
+			seat.WriteString(" didn't play")
+		}
+
+		r.line("%s", seat.String())
+	}
+}
+
+func streetTitle(s Street) string {
+	switch s {
+	case Flop:
+		return "Flop"
+	case Turn:
+		return "Turn"
+	default:
+		return "River"
+	}
+}
+
+// boardCards returns the dealt cards of a board separated by spaces
+func boardCards(board CommunityCards) string {
+	var cards []byte
+	for _, c := range []Card{board.Flop[0], board.Flop[1], board.Flop[2], board.Turn, board.River} {
+		if c == "" {
+			break
+		}
+		if len(cards) > 0 {
+			cards = append(cards, ' ')
+		}
+		cards = append(cards, c...)
+	}
+	return string(cards)
+}
+
+// renderAmount formats amount the way PokerStars does, e.g. $5, $0.10 or $5.20
+func renderAmount(amount float64) string {
+	if amount == math.Trunc(amount) {
+		return "$" + strconv.FormatFloat(amount, 'f', 0, 64)
+	}
+	return "$" + strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,124 @@
+package hands
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// renderCorpus is the test corpus of hands the parser handles in full
+var renderCorpus = map[string]string{
+	"zoom":                    testHands,
+	"cash game":               cashGame2,
+	"multiple winners":        multipleWinnersHand,
+	"uncalled bet":            uncalledBetHand,
+	"run it twice":            runItTwice,
+	"rit player won both":     runItTwicePlayerWonBothBoards,
+	"all folded before flop":  allFoldedBeforeFlop,
+	"rit split on second run": ritEdgeCaseHand,
+}
+
+// parseText parses every hand in text, failing the test on any error
+func parseText(t *testing.T, text []byte) []Hand {
+	t.Helper()
+	handChan := make(chan handImport, 100)
+	_, ok, scanErr := scanHands("render", bufio.NewScanner(bytes.NewReader(text)), encodingUTF8, filePos{}, false, handChan)
+	close(handChan)
+
+	if !ok || scanErr != nil {
+		t.Fatalf("wanted hands to be parsed but got ok=%v, err=%v", ok, scanErr)
+	}
+
+	var parsed []Hand
+	for hi := range handChan {
+		if hi.handErr != nil {
+			t.Fatalf("unexpected error parsing hand: %v\n%s", hi.handErr, text)
+		}
+		parsed = append(parsed, hi.hand)
+	}
+	return parsed
+}
+
+func TestRenderHandRoundTrip(t *testing.T) {
+	for name, text := range renderCorpus {
+		t.Run(name, func(t *testing.T) {
+			want := parseText(t, []byte(text))[0]
+
+			rendered := RenderHand(want)
+			got := parseText(t, rendered)
+
+			if len(got) != 1 {
+				t.Fatalf("wanted 1 hand from rendered text but got %d:\n%s", len(got), rendered)
+			}
+
+			if !reflect.DeepEqual(got[0], want) {
+				t.Errorf("wanted %#v\nbut got %#v\nfrom rendered text:\n%s", want, got[0], rendered)
+			}
+		})
+	}
+}
+
+func TestHandWriter(t *testing.T) {
+	var want []Hand
+	for _, text := range []string{cashGame2, runItTwice, allFoldedBeforeFlop} {
+		want = append(want, parseText(t, []byte(text))...)
+	}
+
+	var buf bytes.Buffer
+	hw := NewHandWriter(&buf)
+	for _, h := range want {
+		if err := hw.Write(h); err != nil {
+			t.Fatalf("unexpected error writing hand: %v", err)
+		}
+	}
+
+	got := parseText(t, buf.Bytes())
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wanted %d hands to survive writing but got %#v", len(want), got)
+	}
+}
+
+func TestRenderHand(t *testing.T) {
+	h := parseText(t, []byte(runItTwice))[0]
+	rendered := string(RenderHand(h))
+
+	wantLines := []string{
+		"PokerStars Hand #254607988518:  Hold'em No Limit ($0.02/$0.05 USD) - 2025/01/29 16:30:35 UTC [2025/01/29 11:30:35 ET]",
+		"Table 'Donati' 6-max Seat #1 is the button",
+		"Seat 2: KavarzE ($15.14 in chips)",
+		"Dealt to KavarzE [Jc Js]",
+		"ThxWasOby3: raises $0.10 to $0.15",
+		"KavarzE: raises $0.45 to $0.60",
+		"ThxWasOby3: raises $0.72 to $1.32",
+		"ThxWasOby3: raises $2.09 to $3.90",
+		"*** FIRST RIVER *** [7d 2h 8h Jh] [3d]",
+		"*** SECOND RIVER *** [7d 2h 8h Jh] [Qh]",
+		"ThxWasOby3 collected $5.02 from pot",
+		"Total pot $10.49 | Rake $0.44",
+		"SECOND Board [7d 2h 8h Jh Qh]",
+	}
+
+	for _, line := range wantLines {
+		if !strings.Contains(rendered, line+"\n") {
+			t.Errorf("wanted line %q in rendered hand:\n%s", line, rendered)
+		}
+	}
+}
+
+func TestRenderAmount(t *testing.T) {
+	cases := map[float64]string{
+		5:     "$5",
+		0.1:   "$0.10",
+		5.2:   "$5.20",
+		10.49: "$10.49",
+		0:     "$0",
+	}
+
+	for amount, want := range cases {
+		if got := renderAmount(amount); got != want {
+			t.Errorf("wanted %q for %v but got %q", want, amount, got)
+		}
+	}
+}
@@ -3,9 +3,9 @@
 package hands
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 )
 
@@ -29,7 +29,13 @@ const (
 
 // Currencies constants
 const (
-	Dollar string = "$"
+	Dollar      string = "$"
+	CurrencyUSD string = "USD"
+)
+
+// Site constants
+const (
+	SitePokerStars string = "PokerStars"
 )
 
 // Global Errs
@@ -72,6 +78,7 @@ type Hand struct {
 	Players  []Player
 	Actions  []Action
 	Summary  Summary
+	Warnings []*ParseError // lines that were not recognised, and lines skipped by ParseLenient as they could not be parsed
 }
 
 // Metadata defines important information about a Hand to help identify it
@@ -79,6 +86,20 @@ type Metadata struct {
 	ID         string
 	Date       time.Time
 	ButtonSeat int
+	Site       string
+	Table      string
+	Hero       string // the player whose hole cards were dealt to the history's owner, if known
+}
+
+// HandKey uniquely identifies a hand across every site the hand was imported from
+type HandKey struct {
+	Site string
+	ID   string
+}
+
+// Key returns the HandKey identifying the hand described by the Metadata
+func (m Metadata) Key() HandKey {
+	return HandKey{Site: m.Site, ID: m.ID}
 }
 
 // Summary groups data from the hand summary section. It is used to report the final overall outcome of the hand.
@@ -129,17 +150,23 @@ type Winner struct {
 	Board      int
 }
 
-func (t ActionType) String() string {
-	return string(t)
-}
+// Blinds returns the small and big blind of the hand, taken from the blinds posted. When no small blind was posted
+// it is assumed to be half the big blind.
+func (h Hand) Blinds() (small, big float64) {
+	for _, a := range h.Actions {
+		if a.ActionType == ActionPost {
+			big = max(big, a.Amount)
+		}
+	}
 
-func (s *Street) next(line []byte) {
-	switch {
-	case bytes.Contains(line, flopSignifier):
-		*s = Flop
-	case bytes.Contains(line, turnSignifier):
-		*s = Turn
-	case bytes.Contains(line, riverSignifier):
-		*s = River
+	for _, a := range h.Actions {
+		if a.ActionType == ActionPost && a.Amount < big {
+			return a.Amount, big
+		}
 	}
+	return math.Round(big*50) / 100, big
+}
+
+func (t ActionType) String() string {
+	return string(t)
 }
@@ -0,0 +1,195 @@
+package hands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// checksumLen is the maximum number of leading bytes of a file covered by LedgerEntry.Checksum
+const checksumLen int64 = 4096
+
+const ledgerVersion = 1
+
+// LedgerEntry records how much of a hand history file has already been imported.
+type LedgerEntry struct {
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+	Checksum string    `json:"checksum"` // hex sha256 of the first min(Offset, 4096) bytes, detects replaced files
+	Offset   int64     `json:"offset"`   // byte offset just past the last complete hand that was parsed
+	Lines    int       `json:"lines"`    // newlines before Offset, so that line numbers in errors are file relative
+}
+
+// Ledger tracks the import progress of every file in a hand history folder, so that subsequent imports only
+// parse hands appended since the previous run rather than moving files out of the way. A Ledger is safe for
+// concurrent use.
+type Ledger struct {
+	mu      sync.Mutex
+	entries map[string]LedgerEntry
+	pending map[string]LedgerEntry
+}
+
+type ledgerFile struct {
+	Version int                    `json:"version"`
+	Files   map[string]LedgerEntry `json:"files"`
+}
+
+// NewLedger returns an empty Ledger ready for use.
+func NewLedger() *Ledger {
+	return &Ledger{
+		entries: map[string]LedgerEntry{},
+		pending: map[string]LedgerEntry{},
+	}
+}
+
+// ReadLedger reads a ledger previously written by Ledger.WriteTo.
+func ReadLedger(r io.Reader) (*Ledger, error) {
+	var lf ledgerFile
+	if err := json.NewDecoder(r).Decode(&lf); err != nil {
+		return nil, fmt.Errorf("decoding ledger: %w", err)
+	}
+
+	if lf.Version != ledgerVersion {
+		return nil, fmt.Errorf("unsupported ledger version %d", lf.Version)
+	}
+
+	l := NewLedger()
+	for k, v := range lf.Files {
+		l.entries[k] = v
+	}
+	return l, nil
+}
+
+// WriteTo writes the committed ledger entries to w as JSON.
+func (l *Ledger) WriteTo(w io.Writer) (int64, error) {
+	l.mu.Lock()
+	lf := ledgerFile{Version: ledgerVersion, Files: l.entries}
+	data, err := json.MarshalIndent(lf, "", "  ")
+	l.mu.Unlock()
+
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// Entry returns the committed ledger entry for the file at path.
+func (l *Ledger) Entry(path string) (LedgerEntry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.entries[path]
+	return e, ok
+}
+
+// resumePos returns the position that parsing of filename, currently described by info, should start from. skip
+// is true when the file is unchanged since it was last recorded. A nil Ledger always parses the whole file.
+func (l *Ledger) resumePos(filesystem fs.FS, filename string, info fs.FileInfo) (pos filePos, skip bool, err error) {
+	if l == nil {
+		return filePos{}, false, nil
+	}
+
+	entry, ok := l.Entry(filename)
+	if !ok {
+		return filePos{}, false, nil
+	}
+	pos = filePos{offset: entry.Offset, lines: entry.Lines}
+
+	if info.Size() == entry.Size && info.ModTime().Equal(entry.ModTime) {
+		return pos, true, nil
+	}
+
+	if info.Size() < entry.Offset {
+		return filePos{}, false, nil // the file was truncated, start again
+	}
+
+	checksum, err := fileChecksum(filesystem, filename, entry.Offset)
+	if err != nil {
+		return filePos{}, false, err
+	}
+
+	if checksum != entry.Checksum {
+		return filePos{}, false, nil // the file was replaced, start again
+	}
+
+	return pos, false, nil
+}
+
+// record stages a new entry for filename after it has been parsed up to pos. The entry only becomes visible once
+// it is committed.
+func (l *Ledger) record(filesystem fs.FS, filename string, info fs.FileInfo, pos filePos) error {
+	if l == nil {
+		return nil
+	}
+
+	checksum, err := fileChecksum(filesystem, filename, pos.offset)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending[filename] = LedgerEntry{
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+		Checksum: checksum,
+		Offset:   pos.offset,
+		Lines:    pos.lines,
+	}
+	return nil
+}
+
+// commit promotes the staged entries of every file that was imported without a file error, discarding the others
+// so that those files are parsed again by the next import. It reports whether any entry was promoted.
+func (l *Ledger) commit(results []FileResult) bool {
+	if l == nil {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, r := range results {
+		if r.Err != nil {
+			delete(l.pending, r.Path)
+		}
+	}
+	changed := len(l.pending) > 0
+	for f, e := range l.pending {
+		l.entries[f] = e
+	}
+	clear(l.pending)
+	return changed
+}
+
+// rollback discards every staged entry, so that each file is parsed from its last committed position by the next
+// import.
+func (l *Ledger) rollback() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	clear(l.pending)
+}
+
+func fileChecksum(filesystem fs.FS, filename string, offset int64) (string, error) {
+	file, err := filesystem.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.CopyN(h, file, min(offset, checksumLen)); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
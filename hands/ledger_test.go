@@ -0,0 +1,153 @@
+package hands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLedgerIncrementalImport(t *testing.T) {
+	modTime := time.Date(2025, 1, 29, 16, 30, 35, 0, time.UTC)
+	// PokerStars follows every hand with blank lines
+	fileSystem := fstest.MapFS{
+		"zoom.txt": {Data: []byte(testHands + "\n\n\n"), ModTime: modTime},
+	}
+	ledger := NewLedger()
+	var imported []Hand
+	opts := ExportOptions{Index: NewHandIndex(), Ledger: ledger, OnHand: func(h Hand) error {
+		imported = append(imported, h)
+		return nil
+	}}
+
+	importAndAssert := func(t *testing.T, wantHands, wantDuplicates int) ExportResult {
+		t.Helper()
+		result := ExportHandsWithOptions(fileSystem, opts)
+
+		if result.HandsCount() != wantHands {
+			t.Errorf("wanted %d hands parsed but got %d", wantHands, result.HandsCount())
+		}
+
+		if result.DuplicateCount() != wantDuplicates {
+			t.Errorf("wanted %d duplicates but got %d", wantDuplicates, result.DuplicateCount())
+		}
+
+		if result.FileErrorCount() != 0 {
+			t.Errorf("wanted no file errors but got %#v", result.FileResults)
+		}
+		return result
+	}
+
+	appendData := func(data string) {
+		f := fileSystem["zoom.txt"]
+		f.Data = append(f.Data, data...)
+		f.ModTime = f.ModTime.Add(time.Minute)
+	}
+
+	t.Run("first import parses the whole file", func(t *testing.T) {
+		importAndAssert(t, 1, 0)
+
+		entry, ok := ledger.Entry("zoom.txt")
+		if !ok {
+			t.Fatal("wanted a ledger entry for zoom.txt but there wasn't one")
+		}
+
+		if entry.Offset != int64(len(testHands)+3) {
+			t.Errorf("wanted offset %d but got %d", len(testHands)+3, entry.Offset)
+		}
+	})
+
+	t.Run("unchanged file is skipped", func(t *testing.T) {
+		importAndAssert(t, 0, 0)
+	})
+
+	t.Run("only appended hands are parsed", func(t *testing.T) {
+		appendData(runItTwice + "\n\n\n")
+
+		importAndAssert(t, 1, 0)
+	})
+
+	t.Run("hand still being written is left for the next import", func(t *testing.T) {
+		before, _ := ledger.Entry("zoom.txt")
+		summaryIdx := strings.Index(cashGame2, "*** SUMMARY ***")
+		potIdx := strings.Index(cashGame2, "Board [")
+
+		// the hand is flushed in chunks, the summary is cut short after its pot line and before its blank lines
+		for _, chunk := range []string{cashGame2[:summaryIdx], cashGame2[summaryIdx:potIdx], cashGame2[potIdx:]} {
+			appendData(chunk)
+			importAndAssert(t, 0, 0)
+
+			// the offset stays at the start of the unfinished hand
+			if after, _ := ledger.Entry("zoom.txt"); after.Offset != before.Offset {
+				t.Errorf("wanted offset %d but got %d", before.Offset, after.Offset)
+			}
+		}
+
+		imported = nil
+		appendData("\n\n\n")
+		importAndAssert(t, 1, 0)
+
+		if len(imported) != 1 || imported[0].Summary.CommunityCards[0].River != "8c" || len(imported[0].Summary.Winners) != 1 {
+			t.Errorf("wanted the whole hand imported with its board and winner but got %#v", imported)
+		}
+	})
+
+	t.Run("replaced file is parsed from the start", func(t *testing.T) {
+		f := fileSystem["zoom.txt"]
+		f.Data = []byte(multipleWinnersHand + "\n\n\n" + testHands + "\n\n\n" + runItTwice + "\n\n\n" + cashGame2 + "\n\n\n")
+		f.ModTime = f.ModTime.Add(time.Minute)
+
+		importAndAssert(t, 1, 3)
+	})
+
+	t.Run("appended duplicates move the ledger on", func(t *testing.T) {
+		before, _ := ledger.Entry("zoom.txt")
+		appendData(testHands + "\n\n\n")
+
+		if result := importAndAssert(t, 0, 1); !result.LedgerChanged {
+			t.Error("wanted the ledger to change")
+		}
+		if after, _ := ledger.Entry("zoom.txt"); after.Offset != before.Offset+int64(len(testHands)+3) {
+			t.Errorf("wanted offset %d but got %d", before.Offset+int64(len(testHands)+3), after.Offset)
+		}
+
+		if result := importAndAssert(t, 0, 0); result.LedgerChanged {
+			t.Error("wanted an unchanged file to leave the ledger unchanged")
+		}
+	})
+}
+
+func TestLedgerReadWrite(t *testing.T) {
+	t.Run("written ledger can be read back", func(t *testing.T) {
+		fileSystem := fstest.MapFS{
+			"zoom.txt": {Data: []byte(testHands), ModTime: time.Date(2025, 1, 21, 20, 51, 32, 0, time.UTC)},
+		}
+		ledger := NewLedger()
+		ExportHandsWithOptions(fileSystem, ExportOptions{Ledger: ledger})
+
+		var buf bytes.Buffer
+		if _, err := ledger.WriteTo(&buf); err != nil {
+			t.Fatalf("unexpected error writing ledger: %v", err)
+		}
+
+		got, err := ReadLedger(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error reading ledger: %v", err)
+		}
+
+		want, _ := ledger.Entry("zoom.txt")
+		gotEntry, ok := got.Entry("zoom.txt")
+		if !ok || !gotEntry.ModTime.Equal(want.ModTime) || gotEntry.Offset != want.Offset || gotEntry.Checksum != want.Checksum {
+			t.Errorf("wanted %#v but got %#v", want, gotEntry)
+		}
+	})
+
+	t.Run("unknown version returns an error", func(t *testing.T) {
+		_, err := ReadLedger(strings.NewReader(`{"version": 99, "files": {}}`))
+
+		if err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+}
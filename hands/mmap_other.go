@@ -0,0 +1,10 @@
+//go:build !unix
+
+package hands
+
+import "io/fs"
+
+// mapFile reports that file can't be mapped into memory on this platform, so it is read as a stream.
+func mapFile(file fs.File) (data []byte, unmap func() error, mapped bool) {
+	return nil, nil, false
+}
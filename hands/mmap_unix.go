@@ -0,0 +1,30 @@
+//go:build unix
+
+package hands
+
+import (
+	"io/fs"
+	"math"
+	"os"
+	"syscall"
+)
+
+// mapFile maps the contents of file into memory, returning them along with a function to unmap them. mapped is false
+// when file can't be mapped, such as a file that is not on disk or is empty, and should be read as a stream instead.
+func mapFile(file fs.File) (data []byte, unmap func() error, mapped bool) {
+	f, ok := file.(*os.File)
+	if !ok {
+		return nil, nil, false
+	}
+
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() || info.Size() == 0 || info.Size() > math.MaxInt {
+		return nil, nil, false
+	}
+
+	data, err = syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, false
+	}
+	return data, func() error { return syscall.Munmap(data) }, true
+}
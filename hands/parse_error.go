@@ -0,0 +1,89 @@
+package hands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNoSummary indicates that a hand ended without a summary section
+	ErrNoSummary = errors.New("error no summary found")
+
+	// ErrHandTooLarge indicates a hand larger than maxHandSize, usually hands run together by a corrupted delimiter
+	ErrHandTooLarge = errors.New("error hand too large to parse")
+
+	// ErrNoButton indicates that a hand's table line, which names the button seat, is missing or names no button
+	ErrNoButton = errors.New("error no button seat found")
+
+	// ErrUnrecognisedLine indicates a line of a hand that the parser does not understand, it is reported as one of
+	// the hand's warnings rather than failing the hand
+	ErrUnrecognisedLine = errors.New("error line not recognised")
+)
+
+// ParseErrorKind classifies the part of a hand a ParseError was found in.
+type ParseErrorKind string
+
+// ParseError kinds
+const (
+	KindNoHandID ParseErrorKind = "no_hand_id" // the hand header has no hand number
+	KindMetadata ParseErrorKind = "metadata"   // the hand header or table line could not be parsed
+	KindAction   ParseErrorKind = "action"
+	KindPlayer   ParseErrorKind = "player"
+	KindWinner   ParseErrorKind = "winner"
+	KindSummary  ParseErrorKind = "summary"   // the summary is missing or its pot could not be parsed
+	KindTooLarge ParseErrorKind = "too_large" // the hand is too large to parse, see ErrHandTooLarge
+
+	KindUnrecognised ParseErrorKind = "unrecognised" // a line the parser does not understand, never fails the hand
+)
+
+// ParseError describes a hand that could not be parsed and where in its file the problem is. Err holds the
+// underlying error, so errors.Is matches the sentinel errors such as ErrFailToParseAction.
+type ParseError struct {
+	File   string
+	HandID string // empty if the hand ID could not be parsed
+	Line   int    // 1-based line number within the file of the offending line
+	Offset int64  // byte offset within the file of the start of the offending line
+	Text   string // the offending line, empty if the problem is not with a single line
+	Kind   ParseErrorKind
+	Err    error
+}
+
+// Error returns the error in the form "file:line: hand ID: kind: err: "text"".
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.File)
+	if e.Line > 0 {
+		b.WriteString(":" + strconv.Itoa(e.Line))
+	}
+	b.WriteString(": ")
+	if e.HandID != "" {
+		b.WriteString("hand " + e.HandID + ": ")
+	}
+	fmt.Fprintf(&b, "%s: %v", e.Kind, e.Err)
+	if e.Text != "" {
+		fmt.Fprintf(&b, ": %q", e.Text)
+	}
+	return b.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// lineError returns a ParseError for line, the lineIdx'th line (from 0) of a hand, which starts offset bytes into
+// the hand. The file position is filled in once the hand's position is known, see ParseError.locate.
+func lineError(kind ParseErrorKind, err error, line []byte, lineIdx int, offset int64) *ParseError {
+	return &ParseError{Kind: kind, Err: err, Text: string(line), Line: lineIdx + 1, Offset: offset}
+}
+
+// locate moves a ParseError positioned relative to its hand to the hand's position in file.
+func (e *ParseError) locate(file, handID string, pos filePos) *ParseError {
+	e.File = file
+	e.HandID = handID
+	e.Offset += pos.offset
+	e.Line += pos.lines
+	return e
+}
@@ -0,0 +1,150 @@
+package hands
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestParseErrorError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *ParseError
+		want string
+	}{
+		{
+			name: "every field",
+			err: &ParseError{
+				File: "zoom.txt", HandID: "123", Line: 12, Offset: 345, Text: "KavarzE: raises 0.45 to 0.60",
+				Kind: KindAction, Err: ActionParseError("no currency"),
+			},
+			want: `zoom.txt:12: hand 123: action: error no action found on text line: no currency: "KavarzE: raises 0.45 to 0.60"`,
+		},
+		{
+			name: "no hand ID or line text",
+			err:  &ParseError{File: "zoom.txt", Line: 1, Kind: KindSummary, Err: ErrNoSummary},
+			want: "zoom.txt:1: summary: error no summary found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("wanted %q but got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// parseErrors returns the ParseError of every hand in fileSystem that failed to parse
+func parseErrors(t *testing.T, fileSystem fstest.MapFS, ledger *Ledger) []*ParseError {
+	t.Helper()
+	var parseErrs []*ParseError
+	for h := range streamHands(fileSystem, mapDir(t, fileSystem), ledger, nil) {
+		if h.handErr == nil {
+			continue
+		}
+
+		var parseErr *ParseError
+		if !errors.As(h.handErr, &parseErr) {
+			t.Fatalf("wanted a *ParseError but got %#v", h.handErr)
+		}
+		parseErrs = append(parseErrs, parseErr)
+	}
+	return parseErrs
+}
+
+// assertPosition checks that the line number, offset and text of err agree with the file's data
+func assertPosition(t *testing.T, data []byte, err *ParseError) {
+	t.Helper()
+	if err.Offset < 0 || err.Offset > int64(len(data)) {
+		t.Fatalf("offset %d is outside the file", err.Offset)
+	}
+
+	line, _, _ := bytes.Cut(data[err.Offset:], newLine)
+	if string(line) != err.Text {
+		t.Errorf("wanted the line at offset %d to be %q but it was %q", err.Offset, err.Text, line)
+	}
+
+	if wantLine := bytes.Count(data[:err.Offset], newLine) + 1; err.Line != wantLine {
+		t.Errorf("wanted line %d but got %d", wantLine, err.Line)
+	}
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	data := []byte(testHands + "\n\n\n" + brokenHands)
+	fileSystem := fstest.MapFS{
+		"zoom.txt":   {Data: data},
+		"random.txt": {Data: []byte("Random non-hand data, whoops!\nmore data")},
+	}
+
+	parseErrs := parseErrors(t, fileSystem, nil)
+	if len(parseErrs) != 2 {
+		t.Fatalf("wanted 2 hand errors but got %d", len(parseErrs))
+	}
+
+	for _, err := range parseErrs {
+		switch err.File {
+		case "zoom.txt":
+			if err.HandID != "254671591484" || err.Kind != KindAction || !errors.Is(err, ErrFailToParseAction) {
+				t.Errorf("wanted an action error in hand 254671591484 but got %v", err)
+			}
+			assertPosition(t, data, err)
+
+		case "random.txt":
+			if err.Kind != KindNoHandID || !errors.Is(err, ErrNoHandID) {
+				t.Errorf("wanted a missing hand ID error but got %v", err)
+			}
+			if err.Line != 1 || err.Offset != 0 || err.Text != "Random non-hand data, whoops!" {
+				t.Errorf("wanted the error on the first line but got %#v", err)
+			}
+		}
+	}
+}
+
+func TestParseErrorPositionIncremental(t *testing.T) {
+	modTime := time.Date(2025, 1, 29, 16, 30, 35, 0, time.UTC)
+	fileSystem := fstest.MapFS{
+		"zoom.txt": {Data: []byte(testHands), ModTime: modTime},
+	}
+	ledger := NewLedger()
+
+	if errs := parseErrors(t, fileSystem, ledger); len(errs) != 0 {
+		t.Fatalf("wanted no hand errors but got %v", errs)
+	}
+	ledger.commit([]FileResult{{Path: "zoom.txt"}})
+
+	// a hand without a hand number, whose header line lost its "PokerStars " prefix to the delimiter
+	broken := strings.Replace(cashGame2, "Hand #254446123323", "Hand", 1)
+	f := fileSystem["zoom.txt"]
+	f.Data = append(f.Data, "\n\n\n"+brokenHands+"\n\n\n"+broken+"\n\n\n"...)
+	f.ModTime = modTime.Add(time.Minute)
+
+	parseErrs := parseErrors(t, fileSystem, ledger)
+	if len(parseErrs) != 2 {
+		t.Fatalf("wanted 2 hand errors but got %d", len(parseErrs))
+	}
+
+	kinds := map[ParseErrorKind]bool{}
+	for _, err := range parseErrs {
+		kinds[err.Kind] = true
+		assertPosition(t, f.Data, err)
+	}
+
+	if !kinds[KindAction] || !kinds[KindNoHandID] {
+		t.Errorf("wanted an action and a missing hand ID error but got %v", parseErrs)
+	}
+}
+
+func mapDir(t *testing.T, fileSystem fstest.MapFS) []fs.DirEntry {
+	t.Helper()
+	dir, err := fs.ReadDir(fileSystem, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
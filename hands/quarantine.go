@@ -0,0 +1,163 @@
+package hands
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+const quarantineVersion = 1
+
+// QuarantinedHand is a hand that failed to parse, kept along with its error so that the problem can be reported
+// and the hand parsed again after a parser upgrade.
+type QuarantinedHand struct {
+	File      string         `json:"file"`
+	HandID    string         `json:"hand_id,omitempty"`
+	Kind      ParseErrorKind `json:"kind"`
+	Error     string         `json:"error"`
+	Line      int            `json:"line"`       // line number within File of the offending line, see ParseError
+	Start     int64          `json:"start"`      // byte offset within File of the start of the hand
+	StartLine int            `json:"start_line"` // newlines within File before Start
+	Text      string         `json:"text"`       // the full text of the hand
+}
+
+// Quarantine collects the hands that fail to parse during an import. A hand read again from the same place in the
+// same file, as happens when a file is imported again, is only kept once. A Quarantine is not safe for concurrent use.
+type Quarantine struct {
+	hands []QuarantinedHand
+	seen  map[quarantineKey]bool
+}
+
+// quarantineKey identifies a quarantined hand by where it was read from and its text.
+type quarantineKey struct {
+	file  string
+	start int64
+	text  string
+}
+
+type quarantineFile struct {
+	Version int               `json:"version"`
+	Hands   []QuarantinedHand `json:"hands"`
+}
+
+// NewQuarantine returns an empty Quarantine ready for use.
+func NewQuarantine() *Quarantine {
+	return &Quarantine{}
+}
+
+// ReadQuarantine reads a quarantine previously written by Quarantine.WriteTo.
+func ReadQuarantine(r io.Reader) (*Quarantine, error) {
+	var qf quarantineFile
+	if err := json.NewDecoder(r).Decode(&qf); err != nil {
+		return nil, fmt.Errorf("decoding quarantine: %w", err)
+	}
+
+	if qf.Version != quarantineVersion {
+		return nil, fmt.Errorf("unsupported quarantine version %d", qf.Version)
+	}
+
+	q := NewQuarantine()
+	for _, qh := range qf.Hands {
+		q.insert(qh)
+	}
+	return q, nil
+}
+
+// WriteTo writes the quarantined hands to w as JSON, ordered by file and position so the output is stable.
+func (q *Quarantine) WriteTo(w io.Writer) (int64, error) {
+	qf := quarantineFile{Version: quarantineVersion, Hands: q.Hands()}
+	slices.SortFunc(qf.Hands, func(a, b QuarantinedHand) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Start, b.Start))
+	})
+
+	data, err := json.MarshalIndent(qf, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// Hands returns a copy of the quarantined hands.
+func (q *Quarantine) Hands() []QuarantinedHand {
+	return slices.Clone(q.hands)
+}
+
+// Len returns the number of quarantined hands.
+func (q *Quarantine) Len() int {
+	return len(q.hands)
+}
+
+// Exclude records the hands of other as already quarantined, so that they are not added to q when read again, as
+// happens when a file that failed is imported again.
+func (q *Quarantine) Exclude(other *Quarantine) {
+	if q.seen == nil {
+		q.seen = map[quarantineKey]bool{}
+	}
+	for _, qh := range other.hands {
+		q.seen[qh.key()] = true
+	}
+}
+
+// add quarantines a hand that failed to parse.
+func (q *Quarantine) add(h handImport) {
+	if q == nil {
+		return
+	}
+
+	qh := QuarantinedHand{
+		File:      h.filePath,
+		Error:     h.handErr.Error(),
+		Start:     h.handStart.offset,
+		StartLine: h.handStart.lines,
+		Text:      string(h.handText),
+	}
+
+	var parseErr *ParseError
+	if errors.As(h.handErr, &parseErr) {
+		qh.HandID = parseErr.HandID
+		qh.Kind = parseErr.Kind
+		qh.Line = parseErr.Line
+	}
+	q.insert(qh)
+}
+
+// insert appends qh unless the same hand has already been quarantined.
+func (q *Quarantine) insert(qh QuarantinedHand) {
+	key := qh.key()
+	if q.seen[key] {
+		return
+	}
+
+	if q.seen == nil {
+		q.seen = map[quarantineKey]bool{}
+	}
+	q.seen[key] = true
+	q.hands = append(q.hands, qh)
+}
+
+func (qh QuarantinedHand) key() quarantineKey {
+	return quarantineKey{file: qh.File, start: qh.Start, text: qh.Text}
+}
+
+// Retry parses every quarantined hand again, returning the hands that now parse and a Quarantine of those that
+// still fail along with their new errors.
+func (q *Quarantine) Retry() ([]Hand, *Quarantine) {
+	var parsed []Hand
+	remaining := NewQuarantine()
+
+	var parser handParser
+	for _, qh := range q.hands {
+		h := parser.parseHand(qh.File, []byte(qh.Text), filePos{offset: qh.Start, lines: qh.StartLine})
+		if h.handErr != nil {
+			remaining.add(h)
+			continue
+		}
+		parsed = append(parsed, h.hand)
+	}
+	return parsed, remaining
+}
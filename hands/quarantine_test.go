@@ -0,0 +1,105 @@
+package hands
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestQuarantine(t *testing.T) {
+	noHandID := strings.Replace(cashGame2, "Hand #254446123323", "Hand", 1)
+	data := []byte(testHands + "\n\n\n" + noHandID + "\n\n\n" + brokenHands)
+	fileSystem := fstest.MapFS{
+		"zoom.txt": {Data: data},
+	}
+
+	quarantine := NewQuarantine()
+	result := ExportHandsWithOptions(fileSystem, ExportOptions{Quarantine: quarantine})
+
+	if quarantine.Len() != result.HandErrCount() || quarantine.Len() != 2 {
+		t.Fatalf("wanted 2 quarantined hands but got %d", quarantine.Len())
+	}
+
+	t.Run("the full text of each hand is kept", func(t *testing.T) {
+		for _, qh := range quarantine.Hands() {
+			if qh.File != "zoom.txt" || qh.Error == "" || qh.Kind == "" {
+				t.Errorf("wanted the file and error to be recorded but got %#v", qh)
+			}
+
+			if !strings.HasPrefix(qh.Text, "PokerStars ") {
+				t.Errorf("wanted the hand text to start with its header but got %q", qh.Text)
+			}
+
+			if got := string(data[qh.Start : qh.Start+int64(len(qh.Text))]); got != qh.Text {
+				t.Errorf("wanted the text at offset %d to be the quarantined hand but got %q", qh.Start, got)
+			}
+
+			if wantLines := bytes.Count(data[:qh.Start], newLine); qh.StartLine != wantLines {
+				t.Errorf("wanted %d lines before the hand but got %d", wantLines, qh.StartLine)
+			}
+		}
+	})
+
+	t.Run("hands read again are only quarantined once", func(t *testing.T) {
+		ExportHandsWithOptions(fileSystem, ExportOptions{Quarantine: quarantine})
+		if quarantine.Len() != 2 {
+			t.Errorf("wanted the 2 hands to stay quarantined once but got %d", quarantine.Len())
+		}
+
+		again := NewQuarantine()
+		again.Exclude(quarantine)
+		ExportHandsWithOptions(fileSystem, ExportOptions{Quarantine: again})
+		if again.Len() != 0 {
+			t.Errorf("wanted the previously quarantined hands to be excluded but got %#v", again.Hands())
+		}
+	})
+
+	t.Run("quarantines can be read back", func(t *testing.T) {
+		var want bytes.Buffer
+		if _, err := quarantine.WriteTo(&want); err != nil {
+			t.Fatalf("wanted no error but got %v", err)
+		}
+
+		read, err := ReadQuarantine(bytes.NewReader(want.Bytes()))
+		if err != nil {
+			t.Fatalf("wanted no error but got %v", err)
+		}
+
+		var got bytes.Buffer
+		read.WriteTo(&got)
+		if got.String() != want.String() {
+			t.Errorf("wanted\n%s\nbut got\n%s", want.String(), got.String())
+		}
+	})
+
+	t.Run("retrying hands that still fail keeps their errors", func(t *testing.T) {
+		parsed, remaining := quarantine.Retry()
+		if len(parsed) != 0 {
+			t.Errorf("wanted no hands to parse but got %d", len(parsed))
+		}
+
+		if !reflect.DeepEqual(remaining.Hands(), quarantine.Hands()) {
+			t.Errorf("wanted %#v but got %#v", quarantine.Hands(), remaining.Hands())
+		}
+	})
+
+	t.Run("retrying fixed hands imports them", func(t *testing.T) {
+		fixed := quarantine.Hands()
+		for i, qh := range fixed {
+			if qh.Kind == KindNoHandID {
+				fixed[i].Text = strings.Replace(qh.Text, "Hand:", "Hand #254446123323:", 1)
+			}
+		}
+
+		parsed, remaining := (&Quarantine{hands: fixed}).Retry()
+		if len(parsed) != 1 || parsed[0].Metadata.ID != "254446123323" {
+			t.Errorf("wanted hand 254446123323 to parse but got %#v", parsed)
+		}
+
+		if remaining.Len() != 1 || remaining.Hands()[0].Kind != KindAction {
+			t.Errorf("wanted the action error to remain but got %#v", remaining.Hands())
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package hands
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// LineCount is the number of lines matching Pattern that the parser did not recognise during an import.
+type LineCount struct {
+	Pattern string // the line with player names replaced by <player>, numbers by N and quoted text by "..."
+	Example string // the first line seen matching Pattern
+	Count   int
+}
+
+// lineCounter aggregates the unrecognised lines of every hand in an import by their pattern.
+type lineCounter struct {
+	counts map[string]*LineCount
+}
+
+func newLineCounter() *lineCounter {
+	return &lineCounter{counts: map[string]*LineCount{}}
+}
+
+// add counts the unrecognised lines among the warnings of h.
+func (c *lineCounter) add(h Hand) {
+	for _, w := range h.Warnings {
+		if w.Kind != KindUnrecognised {
+			continue
+		}
+
+		pattern := linePattern(w.Text, h.Players)
+		lc, ok := c.counts[pattern]
+		if !ok {
+			lc = &LineCount{Pattern: pattern, Example: w.Text}
+			c.counts[pattern] = lc
+		}
+		lc.Count++
+	}
+}
+
+// lines returns the counted patterns, most frequent first.
+func (c *lineCounter) lines() []LineCount {
+	lines := make([]LineCount, 0, len(c.counts))
+	for _, lc := range c.counts {
+		lines = append(lines, *lc)
+	}
+
+	slices.SortFunc(lines, func(a, b LineCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Pattern, b.Pattern))
+	})
+	return lines
+}
+
+// linePattern generalises line so that lines differing only in who they are about, the amounts in them or what was
+// said in the chat are counted together.
+func linePattern(line string, players []Player) string {
+	// replace longer names first, so that a name containing another is not partially replaced
+	names := make([]string, 0, len(players))
+	for _, p := range players {
+		if p.Username != "" {
+			names = append(names, p.Username)
+		}
+	}
+	slices.SortFunc(names, func(a, b string) int { return cmp.Compare(len(b), len(a)) })
+
+	for _, name := range names {
+		line = strings.ReplaceAll(line, name, "<player>")
+	}
+
+	if start, end := strings.IndexByte(line, '"'), strings.LastIndexByte(line, '"'); start < end {
+		line = line[:start+1] + "..." + line[end:]
+	}
+
+	// numbers, including any decimal point or thousands separators, become N
+	isDigit := func(i int) bool { return i < len(line) && line[i] >= '0' && line[i] <= '9' }
+	isSeparator := func(i int) bool { return i < len(line) && (line[i] == '.' || line[i] == ',') }
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		if !isDigit(i) {
+			b.WriteByte(line[i])
+			continue
+		}
+
+		b.WriteByte('N')
+		for isDigit(i+1) || isSeparator(i+1) && isDigit(i+2) {
+			i++
+		}
+	}
+	return b.String()
+}
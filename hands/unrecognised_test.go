@@ -0,0 +1,66 @@
+package hands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLinePattern(t *testing.T) {
+	players := []Player{{Username: "Kavarz"}, {Username: "KavarzE"}, {Username: "don caco 10"}}
+
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"ManeAlhekine has timed out", "ManeAlhekine has timed out"},
+		{"KavarzE has timed out", "<player> has timed out"},
+		{"don caco 10 will be allowed to play after the button", "<player> will be allowed to play after the button"},
+		{`KavarzE said, "nh 2 u"`, `<player> said, "..."`},
+		{"KavarzE wins the $1,250.50 bounty for eliminating Kavarz", "<player> wins the $N bounty for eliminating <player>"},
+		{"Kavarz cashed out the hand for $3.", "<player> cashed out the hand for $N."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := linePattern(tt.line, players); got != tt.want {
+				t.Errorf("wanted %q but got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExportHandsUnrecognisedLines(t *testing.T) {
+	chat := strings.Replace(cashGame2, "*** FLOP ***", "RE0309 said, \"gl\"\nmaximoIV said, \"ty 2\"\n*** FLOP ***", 1)
+	fileSystem := fstest.MapFS{
+		"broken.txt": {Data: []byte(brokenHands)}, // hand 254671585485 has "ManeAlhekine has timed out"
+		"chat.txt":   {Data: []byte(chat)},
+	}
+
+	var warnings []*ParseError
+	result := ExportHandsWithOptions(fileSystem, ExportOptions{
+		OnHand: func(h Hand) error {
+			warnings = append(warnings, h.Warnings...)
+			return nil
+		},
+		MaxFailRate: 1, // the hands of broken.txt are only passed on if the file succeeds
+	})
+
+	want := []LineCount{
+		{Pattern: `<player> said, "..."`, Example: `RE0309 said, "gl"`, Count: 2},
+		{Pattern: "<player> has timed out", Example: "ManeAlhekine has timed out", Count: 1},
+	}
+	if !reflect.DeepEqual(result.UnrecognisedLines, want) {
+		t.Errorf("wanted %#v but got %#v", want, result.UnrecognisedLines)
+	}
+
+	if len(warnings) != 3 {
+		t.Fatalf("wanted 3 warnings but got %d", len(warnings))
+	}
+	for _, w := range warnings {
+		if w.Kind != KindUnrecognised || w.HandID == "" || w.Line == 0 {
+			t.Errorf("wanted a positioned unrecognised line warning but got %#v", w)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package hands
+
+import (
+	"context"
+	"io/fs"
+	"time"
+)
+
+// DefaultWatchInterval is how often Watch polls the hand history folder when WatchOptions.Interval is not set.
+const DefaultWatchInterval = 2 * time.Second
+
+// WatchOptions configures Watch.
+type WatchOptions struct {
+	// ExportOptions configures each poll. A Ledger and Index are created if nil, so that every poll only parses the
+	// hands completed since the previous one.
+	ExportOptions
+
+	// Interval is the time between polls, DefaultWatchInterval if zero.
+	Interval time.Duration
+
+	// OnPoll, if set, is called with the result of every poll once its hands have been passed to OnHand.
+	OnPoll func(ExportResult)
+}
+
+// Watch polls a hand history folder until ctx is done, importing the hands appended to each file as they are
+// completed. A hand that is still being written is left until a later poll finds the blank line written after it.
+// Polling rather than file system notifications is used so that Watch works on every platform and file system,
+// including network drives. Watch returns ctx.Err() once ctx is done.
+func Watch(ctx context.Context, fileSystem fs.FS, opts WatchOptions) error {
+	if opts.Ledger == nil {
+		opts.Ledger = NewLedger()
+	}
+	if opts.Index == nil {
+		opts.Index = NewHandIndex()
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultWatchInterval
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for ctx.Err() == nil {
+		result := ExportHandsWithOptions(fileSystem, opts.ExportOptions)
+		if opts.OnPoll != nil {
+			opts.OnPoll(result)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+	return ctx.Err()
+}
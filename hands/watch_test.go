@@ -0,0 +1,121 @@
+package hands
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	modTime := time.Date(2025, 1, 29, 16, 30, 35, 0, time.UTC)
+	fileSystem := fstest.MapFS{
+		"zoom.txt": {Data: []byte(testHands + "\n\n\n"), ModTime: modTime},
+	}
+	summaryIdx := strings.Index(cashGame2, "*** SUMMARY ***")
+
+	// each poll appends the next chunk, the second hand is written in two halves
+	appends := []string{cashGame2[:summaryIdx], cashGame2[summaryIdx:] + "\n\n\n", ""}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got []string
+	var perPoll []int
+	polls := 0
+
+	opts := WatchOptions{
+		ExportOptions: ExportOptions{
+			OnHand: func(h Hand) error {
+				got = append(got, h.Metadata.ID)
+				return nil
+			},
+		},
+		Interval: time.Millisecond,
+		OnPoll: func(result ExportResult) {
+			perPoll = append(perPoll, result.HandsCount())
+			if polls == len(appends) {
+				cancel()
+				return
+			}
+
+			f := fileSystem["zoom.txt"]
+			f.Data = append(f.Data, appends[polls]...)
+			f.ModTime = f.ModTime.Add(time.Minute)
+			polls++
+		},
+	}
+
+	err := Watch(ctx, fileSystem, opts)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted context.Canceled but got %v", err)
+	}
+
+	wantPerPoll := []int{1, 0, 1, 0}
+	if !reflect.DeepEqual(perPoll, wantPerPoll) {
+		t.Errorf("wanted %v hands per poll but got %v", wantPerPoll, perPoll)
+	}
+
+	if len(got) != 2 || got[0] == got[1] {
+		t.Errorf("wanted 2 distinct hands but got %v", got)
+	}
+}
+
+func TestWatchHandWrittenInParts(t *testing.T) {
+	flopIdx := strings.Index(cashGame2, "*** FLOP ***")
+	summaryIdx := strings.Index(cashGame2, "*** SUMMARY ***")
+	boardIdx := strings.Index(cashGame2, "Board [")
+
+	// the hand is written a few lines at a time, splitting its summary, and only completed by the blank lines after it
+	fileSystem := fstest.MapFS{
+		"zoom.txt": {Data: []byte(cashGame2[:flopIdx]), ModTime: time.Date(2025, 1, 29, 16, 30, 35, 0, time.UTC)},
+	}
+	appends := []string{cashGame2[flopIdx:summaryIdx], cashGame2[summaryIdx:boardIdx], cashGame2[boardIdx:], "\n\n\n", ""}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got []Hand
+	var perPoll []int
+	polls := 0
+
+	opts := WatchOptions{
+		ExportOptions: ExportOptions{
+			OnHand: func(h Hand) error {
+				got = append(got, h)
+				return nil
+			},
+		},
+		Interval: time.Millisecond,
+		OnPoll: func(result ExportResult) {
+			perPoll = append(perPoll, result.HandsCount())
+			if polls == len(appends) {
+				cancel()
+				return
+			}
+
+			f := fileSystem["zoom.txt"]
+			f.Data = append(f.Data, appends[polls]...)
+			f.ModTime = f.ModTime.Add(time.Minute)
+			polls++
+		},
+	}
+
+	err := Watch(ctx, fileSystem, opts)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted context.Canceled but got %v", err)
+	}
+
+	wantPerPoll := []int{0, 0, 0, 0, 1, 0}
+	if !reflect.DeepEqual(perPoll, wantPerPoll) {
+		t.Errorf("wanted %v hands per poll but got %v", wantPerPoll, perPoll)
+	}
+
+	want := parseText(t, []byte(cashGame2))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wanted the hand as written in one go\n%v\nbut got\n%v", want, got)
+	}
+}
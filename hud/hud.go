@@ -0,0 +1,144 @@
+// Package hud renders a text heads-up display of the players at each active table, for a terminal on a second
+// monitor.
+package hud
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"pokerhud/hands"
+	"pokerhud/stats"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// DefaultActiveWindow is how recently a table's last hand must have been played for it to be shown, relative to
+// the most recent hand at any table.
+const DefaultActiveWindow = 10 * time.Minute
+
+// HUD tracks the statistics of every player and the latest hand at each table. It is not safe for concurrent use.
+type HUD struct {
+	// Hero is the player whose session is shown, if empty the hero of the latest hand is used
+	Hero string
+
+	// ActiveWindow is how recently a table must have been played at to be shown, DefaultActiveWindow if zero
+	ActiveWindow time.Duration
+
+	stats  *stats.Stats
+	hands  []hands.Hand          // ordered by date
+	tables map[string]hands.Hand // latest hand at each table
+}
+
+// New returns a HUD without any hands.
+func New() *HUD {
+	return &HUD{stats: stats.New(), tables: map[string]hands.Hand{}}
+}
+
+// Add updates the HUD with h. Each hand must only be added once.
+func (d *HUD) Add(h hands.Hand) {
+	d.stats.Add(h)
+
+	i, _ := slices.BinarySearchFunc(d.hands, h, func(a, b hands.Hand) int {
+		return cmp.Or(a.Metadata.Date.Compare(b.Metadata.Date), cmp.Compare(a.Metadata.ID, b.Metadata.ID))
+	})
+	d.hands = slices.Insert(d.hands, i, h)
+
+	table := h.Metadata.Table
+	if latest, ok := d.tables[table]; !ok || !h.Metadata.Date.Before(latest.Metadata.Date) {
+		d.tables[table] = h
+	}
+}
+
+// hero returns the player whose session is shown.
+func (d *HUD) hero() string {
+	if d.Hero != "" {
+		return d.Hero
+	}
+	for _, h := range slices.Backward(d.hands) {
+		if h.Metadata.Hero != "" {
+			return h.Metadata.Hero
+		}
+	}
+	return ""
+}
+
+// activeTables returns the latest hand at each active table, most recently played first.
+func (d *HUD) activeTables() []hands.Hand {
+	window := d.ActiveWindow
+	if window <= 0 {
+		window = DefaultActiveWindow
+	}
+
+	var latest []hands.Hand
+	for _, h := range d.tables {
+		latest = append(latest, h)
+	}
+	slices.SortFunc(latest, func(a, b hands.Hand) int {
+		return cmp.Or(b.Metadata.Date.Compare(a.Metadata.Date), cmp.Compare(a.Metadata.Table, b.Metadata.Table))
+	})
+
+	active := latest[:0]
+	for _, h := range latest {
+		if latest[0].Metadata.Date.Sub(h.Metadata.Date) <= window {
+			active = append(active, h)
+		}
+	}
+	return active
+}
+
+// Render writes the HUD to w: the hero's current session followed by a table of the seated players' statistics for
+// every active table.
+func (d *HUD) Render(w io.Writer) error {
+	if len(d.hands) == 0 {
+		_, err := fmt.Fprintln(w, "Waiting for hands...")
+		return err
+	}
+
+	hero := d.hero()
+	if err := d.renderSession(w, hero); err != nil {
+		return err
+	}
+
+	for _, h := range d.activeTables() {
+		if err := d.renderTable(w, h, hero); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *HUD) renderSession(w io.Writer, hero string) error {
+	sessions := stats.Sessions(d.hands, hero, 0)
+	if len(sessions) == 0 {
+		_, err := fmt.Fprintf(w, "Hero: %s  no hands this session\n", cmp.Or(hero, "unknown"))
+		return err
+	}
+
+	s := sessions[len(sessions)-1]
+	_, err := fmt.Fprintf(w, "Hero: %s  Session: %d hands in %s at %s  Net: %+.2f\n",
+		hero, s.Hands, s.Duration().Round(time.Minute), strings.Join(s.Tables, ", "), s.Net)
+	return err
+}
+
+func (d *HUD) renderTable(w io.Writer, h hands.Hand, hero string) error {
+	fmt.Fprintf(w, "\n%s  hand #%s at %s\n",
+		cmp.Or(h.Metadata.Table, "Unknown table"), h.Metadata.ID, h.Metadata.Date.Format(time.TimeOnly))
+
+	players := slices.Clone(h.Players)
+	slices.SortFunc(players, func(a, b hands.Player) int { return cmp.Compare(a.Seat, b.Seat) })
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Seat\tPlayer\tHands\tVPIP\tPFR\t3Bet\tAF\t")
+	for _, p := range players {
+		name := p.Username
+		if name == hero {
+			name = "*" + name
+		}
+		ps, _ := d.stats.Player(p.Username)
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%.1f\t%.1f\t%.1f\t%.2f\t\n",
+			p.Seat, name, ps.Hands, ps.VPIP(), ps.PFR(), ps.ThreeBet(), ps.AF())
+	}
+	return tw.Flush()
+}
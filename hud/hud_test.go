@@ -0,0 +1,111 @@
+package hud
+
+import (
+	"bytes"
+	"pokerhud/hands"
+	"testing"
+	"time"
+)
+
+var sessionStart = time.Date(2025, 1, 29, 16, 0, 0, 0, time.UTC)
+
+// showdownHand returns a heads up hand at table, played at minutes past sessionStart, in which the small blind
+// raises to $0.15 and is called, bets $0.20 on the flop and is called, and the winner takes the $0.70 pot less
+// $0.03 rake at showdown.
+func showdownHand(id, table string, minutes int, smallBlind, bigBlind, winner string) hands.Hand {
+	return hands.Hand{
+		Metadata: hands.Metadata{
+			ID:         id,
+			Date:       sessionStart.Add(time.Duration(minutes) * time.Minute),
+			ButtonSeat: 1,
+			Site:       hands.SitePokerStars,
+			Table:      table,
+			Hero:       "hero",
+		},
+		Players: []hands.Player{{Username: smallBlind, Seat: 1, ChipCount: 5}, {Username: bigBlind, Seat: 2, ChipCount: 5}},
+		Actions: []hands.Action{
+			{Order: 1, PlayerName: smallBlind, Street: hands.Preflop, ActionType: hands.ActionPost, Amount: 0.02},
+			{Order: 2, PlayerName: bigBlind, Street: hands.Preflop, ActionType: hands.ActionPost, Amount: 0.05},
+			{Order: 3, PlayerName: smallBlind, Street: hands.Preflop, ActionType: hands.ActionRaise, Amount: 0.10},
+			{Order: 4, PlayerName: bigBlind, Street: hands.Preflop, ActionType: hands.ActionCall, Amount: 0.10},
+			{Order: 5, PlayerName: bigBlind, Street: hands.Flop, ActionType: hands.ActionCheck},
+			{Order: 6, PlayerName: smallBlind, Street: hands.Flop, ActionType: hands.ActionBet, Amount: 0.20},
+			{Order: 7, PlayerName: bigBlind, Street: hands.Flop, ActionType: hands.ActionCall, Amount: 0.20},
+			{Order: 8, PlayerName: bigBlind, Street: hands.Turn, ActionType: hands.ActionCheck},
+			{Order: 9, PlayerName: smallBlind, Street: hands.Turn, ActionType: hands.ActionCheck},
+			{Order: 10, PlayerName: bigBlind, Street: hands.River, ActionType: hands.ActionCheck},
+			{Order: 11, PlayerName: smallBlind, Street: hands.River, ActionType: hands.ActionCheck},
+		},
+		Summary: hands.Summary{
+			CommunityCards: [2]hands.CommunityCards{{Flop: [3]hands.Card{"2h", "Ts", "Jc"}, Turn: "3h", River: "8c"}},
+			Pot:            0.70,
+			Rake:           0.03,
+			Winners:        []hands.Winner{{PlayerName: winner, Amount: 0.67, Board: 1}},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	// hero loses $0.35 to fish and wins $0.32 from villain twice, opening one of the three hands
+	const bothTables = `Hero: hero  Session: 3 hands in 15m0s at Donati, Halley  Net: +0.29
+
+Halley  hand #3 at 16:05:00
+  Seat   Player  Hands   VPIP   PFR  3Bet    AF
+     1    *hero      3  100.0  33.3   0.0  0.50
+     2  villain      2  100.0  50.0   0.0  1.00
+
+Donati  hand #1 at 15:50:00
+  Seat  Player  Hands   VPIP    PFR  3Bet    AF
+     1    fish      1  100.0  100.0   0.0  1.00
+     2   *hero      3  100.0   33.3   0.0  0.50
+`
+
+	const oneTable = `Hero: villain  Session: 2 hands in 5m0s at Halley  Net: -0.70
+
+Halley  hand #3 at 16:05:00
+  Seat    Player  Hands   VPIP   PFR  3Bet    AF
+     1      hero      3  100.0  33.3   0.0  0.50
+     2  *villain      2  100.0  50.0   0.0  1.00
+`
+
+	tests := []struct {
+		name   string
+		hero   string
+		window time.Duration
+		want   string
+	}{
+		{"tables within the active window are shown", "", 20 * time.Minute, bothTables},
+		{"inactive tables are hidden and the hero can be chosen", "villain", 0, oneTable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New()
+			d.Hero = tt.hero
+			d.ActiveWindow = tt.window
+			d.Add(showdownHand("3", "Halley", 5, "hero", "villain", "hero"))
+			d.Add(showdownHand("1", "Donati", -10, "fish", "hero", "fish"))
+			d.Add(showdownHand("2", "Halley", 0, "villain", "hero", "hero"))
+
+			var buf bytes.Buffer
+			if err := d.Render(&buf); err != nil {
+				t.Fatalf("wanted no error but got %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("wanted\n%s\nbut got\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRenderWithoutHands(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New().Render(&buf); err != nil {
+		t.Fatalf("wanted no error but got %v", err)
+	}
+
+	if got := buf.String(); got != "Waiting for hands...\n" {
+		t.Errorf("wanted a waiting message but got %q", got)
+	}
+}
@@ -0,0 +1,570 @@
+// Package ohh converts hands to and from the Open Hand History (OHH) JSON format, the community standard for
+// exchanging hand histories between trackers and solvers. See https://hh-specs.handhistory.org.
+//
+// Hands are written as objects of the form {"ohh": <hand>}. Amounts follow the spec: a raise holds the total the
+// player raised to on the street, whereas hands.Action holds the amount the bet was raised by.
+//
+// The spec has no notion of the second board of a hand that was run twice. The cards of the second run are written
+// as extra rounds after the first run, holding only the cards that differ from the first board, and the pots won on
+// the second board are marked with the extension field run_number set to 2.
+package ohh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"pokerhud/hands"
+	"time"
+)
+
+// SpecVersion is the version of the OHH spec written by FromHand.
+const SpecVersion = "1.4.6"
+
+// OHH round streets
+const (
+	StreetPreflop  = "Preflop"
+	StreetFlop     = "Flop"
+	StreetTurn     = "Turn"
+	StreetRiver    = "River"
+	StreetShowdown = "Showdown"
+)
+
+// OHH action names
+const (
+	ActionDealtCards = "Dealt Cards"
+	ActionShowsCards = "Shows Cards"
+	ActionMucksCards = "Mucks Cards"
+	ActionPostSB     = "Post SB"
+	ActionPostBB     = "Post BB"
+	ActionPostDead   = "Post Dead"
+	ActionPostExtra  = "Post Extra Blind"
+	ActionFold       = "Fold"
+	ActionCheck      = "Check"
+	ActionBet        = "Bet"
+	ActionRaise      = "Raise"
+	ActionCall       = "Call"
+)
+
+var (
+	errUnknownPlayer = errors.New("error unknown player")
+	errUnknownStreet = errors.New("error unknown OHH street")
+	errUnknownAction = errors.New("error unknown OHH action")
+)
+
+// UnknownPlayerError propagates an errUnknownPlayer error with customised message msg.
+func UnknownPlayerError(msg string) error {
+	return fmt.Errorf("%w: %s", errUnknownPlayer, msg)
+}
+
+// Document is the top level object of an OHH hand history.
+type Document struct {
+	OHH HandHistory `json:"ohh"`
+}
+
+// HandHistory is a single hand in the OHH format.
+type HandHistory struct {
+	SpecVersion      string   `json:"spec_version"`
+	SiteName         string   `json:"site_name"`
+	GameNumber       string   `json:"game_number"`
+	StartDateUTC     string   `json:"start_date_utc"`
+	TableName        string   `json:"table_name"`
+	GameType         string   `json:"game_type"`
+	BetLimit         BetLimit `json:"bet_limit"`
+	TableSize        int      `json:"table_size"`
+	Currency         string   `json:"currency"`
+	DealerSeat       int      `json:"dealer_seat"`
+	SmallBlindAmount float64  `json:"small_blind_amount"`
+	BigBlindAmount   float64  `json:"big_blind_amount"`
+	AnteAmount       float64  `json:"ante_amount"`
+	HeroPlayerID     *int     `json:"hero_player_id,omitempty"`
+	Players          []Player `json:"players"`
+	Rounds           []Round  `json:"rounds"`
+	Pots             []Pot    `json:"pots"`
+}
+
+// BetLimit describes the betting structure of the game.
+type BetLimit struct {
+	BetType string `json:"bet_type"`
+}
+
+// Player is a player seated at the table when the hand started.
+type Player struct {
+	ID            int     `json:"id"`
+	Seat          int     `json:"seat"`
+	Name          string  `json:"name"`
+	StartingStack float64 `json:"starting_stack"`
+}
+
+// Round holds the cards dealt on a street and the actions made on it.
+type Round struct {
+	ID      int          `json:"id"`
+	Street  string       `json:"street"`
+	Cards   []hands.Card `json:"cards,omitempty"`
+	Actions []Action     `json:"actions"`
+}
+
+// Action is a single action within a round.
+type Action struct {
+	ActionNumber int          `json:"action_number"`
+	PlayerID     int          `json:"player_id"`
+	Action       string       `json:"action"`
+	Amount       float64      `json:"amount"`
+	IsAllIn      bool         `json:"is_allin"`
+	Cards        []hands.Card `json:"cards,omitempty"`
+}
+
+// Pot is a pot and the players who won it.
+type Pot struct {
+	Number     int         `json:"number"`
+	Amount     float64     `json:"amount"`
+	Rake       float64     `json:"rake"`
+	RunNumber  int         `json:"run_number,omitempty"` // extension: 2 for pots won on the second board
+	PlayerWins []PlayerWin `json:"player_wins"`
+}
+
+// PlayerWin is the amount a player won from a pot.
+type PlayerWin struct {
+	PlayerID  int     `json:"player_id"`
+	WinAmount float64 `json:"win_amount"`
+}
+
+var toOHHStreet = map[hands.Street]string{
+	hands.Preflop: StreetPreflop,
+	hands.Flop:    StreetFlop,
+	hands.Turn:    StreetTurn,
+	hands.River:   StreetRiver,
+}
+
+var fromOHHStreet = map[string]hands.Street{
+	StreetPreflop: hands.Preflop,
+	StreetFlop:    hands.Flop,
+	StreetTurn:    hands.Turn,
+	StreetRiver:   hands.River,
+}
+
+var toOHHAction = map[hands.ActionType]string{
+	hands.ActionFold:  ActionFold,
+	hands.ActionCheck: ActionCheck,
+	hands.ActionCall:  ActionCall,
+	hands.ActionBet:   ActionBet,
+	hands.ActionRaise: ActionRaise,
+}
+
+var fromOHHAction = map[string]hands.ActionType{
+	ActionFold:      hands.ActionFold,
+	ActionCheck:     hands.ActionCheck,
+	ActionCall:      hands.ActionCall,
+	ActionBet:       hands.ActionBet,
+	ActionRaise:     hands.ActionRaise,
+	ActionPostSB:    hands.ActionPost,
+	ActionPostBB:    hands.ActionPost,
+	ActionPostDead:  hands.ActionPost,
+	ActionPostExtra: hands.ActionPost,
+}
+
+// FromHand converts h to an OHH hand history.
+func FromHand(h hands.Hand) (HandHistory, error) {
+	hh := HandHistory{
+		SpecVersion:  SpecVersion,
+		SiteName:     h.Metadata.Site,
+		GameNumber:   h.Metadata.ID,
+		StartDateUTC: h.Metadata.Date.UTC().Format(time.RFC3339),
+		TableName:    h.Metadata.Table,
+		GameType:     "Holdem",
+		BetLimit:     BetLimit{BetType: "NL"},
+		Currency:     hands.CurrencyUSD,
+		DealerSeat:   h.Metadata.ButtonSeat,
+		Players:      make([]Player, len(h.Players)),
+		Rounds:       []Round{},
+		Pots:         []Pot{},
+	}
+
+	ids := map[string]int{}
+	for i, p := range h.Players {
+		ids[p.Username] = i
+		hh.Players[i] = Player{ID: i, Seat: p.Seat, Name: p.Username, StartingStack: p.ChipCount}
+		hh.TableSize = max(hh.TableSize, p.Seat)
+	}
+
+	if id, ok := ids[h.Metadata.Hero]; ok {
+		hh.HeroPlayerID = &id
+	}
+
+	hh.SmallBlindAmount, hh.BigBlindAmount = h.Blinds()
+
+	rounds, err := fromActions(h, ids)
+	if err != nil {
+		return HandHistory{}, err
+	}
+	hh.Rounds = rounds
+
+	pots, err := fromWinners(h.Summary, ids)
+	if err != nil {
+		return HandHistory{}, err
+	}
+	hh.Pots = pots
+
+	return hh, nil
+}
+
+func fromActions(h hands.Hand, ids map[string]int) ([]Round, error) {
+	var rounds []Round
+	number := 0
+	nextAction := func(round *Round, a Action) {
+		number++
+		a.ActionNumber = number
+		round.Actions = append(round.Actions, a)
+	}
+
+	preflop := Round{ID: 0, Street: StreetPreflop, Actions: []Action{}}
+	for i, p := range h.Players {
+		if p.Username == h.Metadata.Hero && p.Cards[0] != "" {
+			nextAction(&preflop, Action{PlayerID: i, Action: ActionDealtCards, Cards: p.Cards[:]})
+		}
+	}
+	rounds = append(rounds, preflop)
+
+	board := h.Summary.CommunityCards[0]
+	streetCards := map[hands.Street][]hands.Card{
+		hands.Flop:  knownCards(board.Flop[:]...),
+		hands.Turn:  knownCards(board.Turn),
+		hands.River: knownCards(board.River),
+	}
+
+	_, bb := h.Blinds()
+
+	current := &rounds[0]
+	bets := hands.NewStreetBets()
+	for _, a := range h.Actions {
+		id, ok := ids[a.PlayerName]
+		if !ok {
+			return nil, UnknownPlayerError(fmt.Sprintf("%q made action %d", a.PlayerName, a.Order))
+		}
+
+		street, ok := toOHHStreet[a.Street]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", errUnknownStreet, a.Street)
+		}
+
+		for current.Street != street {
+			next := nextStreet(current.Street)
+			if next == "" {
+				return nil, fmt.Errorf("%w: action %d on %q is out of order", errUnknownStreet, a.Order, a.Street)
+			}
+			rounds = append(rounds, Round{ID: len(rounds), Street: next, Cards: streetCards[fromOHHStreet[next]], Actions: []Action{}})
+			current = &rounds[len(rounds)-1]
+		}
+
+		action := Action{PlayerID: id, Amount: a.Amount}
+		switch a.ActionType {
+		case hands.ActionPost:
+			action.Action = ActionPostBB
+			if a.Amount < bb {
+				action.Action = ActionPostSB
+			}
+		case hands.ActionRaise:
+			action.Action = ActionRaise
+			action.Amount = bets.Raise(a.Street, a.PlayerName, a.Amount)
+		default:
+			action.Action = toOHHAction[a.ActionType]
+		}
+
+		if a.ActionType != hands.ActionRaise {
+			bets.Add(a.Street, a.PlayerName, a.Amount)
+		}
+		nextAction(current, action)
+	}
+
+	// streets dealt after the last action, e.g. once every player is all-in
+	for next := nextStreet(current.Street); next != StreetShowdown && len(streetCards[fromOHHStreet[next]]) > 0; next = nextStreet(next) {
+		rounds = append(rounds, Round{ID: len(rounds), Street: next, Cards: streetCards[fromOHHStreet[next]], Actions: []Action{}})
+	}
+
+	rounds = append(rounds, secondRun(h.Summary.CommunityCards, len(rounds))...)
+
+	showdown := Round{Street: StreetShowdown, Actions: []Action{}}
+	for i, p := range h.Players {
+		if p.Username != h.Metadata.Hero && p.Cards[0] != "" {
+			nextAction(&showdown, Action{PlayerID: i, Action: ActionShowsCards, Cards: p.Cards[:]})
+		}
+	}
+	if len(showdown.Actions) > 0 {
+		showdown.ID = len(rounds)
+		rounds = append(rounds, showdown)
+	}
+
+	return rounds, nil
+}
+
+// secondRun returns the rounds dealing the cards of the second board that differ from the first.
+func secondRun(boards [2]hands.CommunityCards, firstID int) []Round {
+	first, second := boards[0], boards[1]
+	if second.River == "" {
+		return nil
+	}
+
+	var rounds []Round
+	add := func(street string, cards ...hands.Card) {
+		rounds = append(rounds, Round{ID: firstID + len(rounds), Street: street, Cards: cards, Actions: []Action{}})
+	}
+
+	if second.Flop != first.Flop {
+		add(StreetFlop, second.Flop[:]...)
+	}
+	if second.Turn != first.Turn || len(rounds) > 0 {
+		add(StreetTurn, second.Turn)
+	}
+	add(StreetRiver, second.River)
+
+	return rounds
+}
+
+func nextStreet(street string) string {
+	switch street {
+	case StreetPreflop:
+		return StreetFlop
+	case StreetFlop:
+		return StreetTurn
+	case StreetTurn:
+		return StreetRiver
+	case StreetRiver:
+		return StreetShowdown
+	default:
+		return ""
+	}
+}
+
+func fromWinners(summary hands.Summary, ids map[string]int) ([]Pot, error) {
+	pots := []Pot{}
+	potForBoard := map[int]int{}
+	var won float64
+
+	for _, w := range summary.Winners {
+		id, ok := ids[w.PlayerName]
+		if !ok {
+			return nil, UnknownPlayerError(fmt.Sprintf("%q won the pot", w.PlayerName))
+		}
+
+		i, ok := potForBoard[w.Board]
+		if !ok {
+			i = len(pots)
+			potForBoard[w.Board] = i
+			pot := Pot{Number: i, PlayerWins: []PlayerWin{}}
+			if w.Board == 2 {
+				pot.RunNumber = 2
+			}
+			pots = append(pots, pot)
+		}
+
+		pots[i].Amount = hands.RoundAmount(pots[i].Amount + w.Amount)
+		pots[i].PlayerWins = append(pots[i].PlayerWins, PlayerWin{PlayerID: id, WinAmount: w.Amount})
+		won += w.Amount
+	}
+
+	if len(pots) == 0 {
+		pots = append(pots, Pot{PlayerWins: []PlayerWin{}})
+	}
+
+	// the rake, and anything else taken from the pot, is charged to the first pot so the pots add up to the total
+	pots[0].Rake = summary.Rake
+	pots[0].Amount = hands.RoundAmount(pots[0].Amount + summary.Pot - won)
+
+	return pots, nil
+}
+
+// ToHand converts an OHH hand history to a hands.Hand.
+func ToHand(hh HandHistory) (hands.Hand, error) {
+	date, err := time.Parse(time.RFC3339, hh.StartDateUTC)
+	if err != nil {
+		return hands.Hand{}, err
+	}
+
+	h := hands.Hand{
+		Metadata: hands.Metadata{
+			ID:         hh.GameNumber,
+			Date:       date.UTC(),
+			ButtonSeat: hh.DealerSeat,
+			Site:       hh.SiteName,
+			Table:      hh.TableName,
+		},
+		Players: make([]hands.Player, len(hh.Players)),
+	}
+
+	names := map[int]string{}
+	seats := map[int]int{}
+	for i, p := range hh.Players {
+		names[p.ID] = p.Name
+		seats[p.ID] = i
+		h.Players[i] = hands.Player{Username: p.Name, Seat: p.Seat, ChipCount: p.StartingStack}
+	}
+
+	if hh.HeroPlayerID != nil {
+		h.Metadata.Hero = names[*hh.HeroPlayerID]
+	}
+
+	order := 0
+	bets := hands.NewStreetBets()
+	lastStreet := hands.Preflop
+	secondRun := false
+	board := &h.Summary.CommunityCards[0]
+
+	for _, round := range hh.Rounds {
+		street, isBettingStreet := fromOHHStreet[round.Street]
+		if !isBettingStreet && round.Street != StreetShowdown {
+			return hands.Hand{}, fmt.Errorf("%w: %q", errUnknownStreet, round.Street)
+		}
+
+		if isBettingStreet && len(round.Cards) > 0 {
+			if !secondRun && streetIndex(street) <= streetIndex(lastStreet) {
+				secondRun = true
+				h.Summary.CommunityCards[1] = h.Summary.CommunityCards[0]
+				board = &h.Summary.CommunityCards[1]
+			}
+			dealCards(board, street, round.Cards)
+			lastStreet = street
+		}
+
+		for _, a := range round.Actions {
+			name, ok := names[a.PlayerID]
+			if !ok {
+				return hands.Hand{}, UnknownPlayerError(fmt.Sprintf("player id %d in action %d", a.PlayerID, a.ActionNumber))
+			}
+
+			switch a.Action {
+			case ActionDealtCards, ActionShowsCards, ActionMucksCards:
+				if len(a.Cards) == 2 {
+					copy(h.Players[seats[a.PlayerID]].Cards[:], a.Cards)
+				}
+				continue
+			}
+
+			actionType, ok := fromOHHAction[a.Action]
+			if !ok {
+				return hands.Hand{}, fmt.Errorf("%w: %q", errUnknownAction, a.Action)
+			}
+
+			amount := a.Amount
+			switch actionType {
+			case hands.ActionRaise:
+				amount = hands.RoundAmount(a.Amount - bets.Highest(street))
+				bets.Raise(street, name, amount)
+			case hands.ActionFold, hands.ActionCheck:
+				amount = 0
+			default:
+				bets.Add(street, name, amount)
+			}
+
+			order++
+			h.Actions = append(h.Actions, hands.Action{
+				PlayerName: name,
+				Order:      order,
+				Street:     street,
+				ActionType: actionType,
+				Amount:     amount,
+			})
+		}
+	}
+
+	for _, pot := range hh.Pots {
+		h.Summary.Pot = hands.RoundAmount(h.Summary.Pot + pot.Amount)
+		h.Summary.Rake = hands.RoundAmount(h.Summary.Rake + pot.Rake)
+
+		boardNum := 1
+		switch {
+		case pot.RunNumber == 2:
+			boardNum = 2
+		case h.Summary.CommunityCards[0].Flop[0] == "":
+			boardNum = 0
+		}
+
+		for _, win := range pot.PlayerWins {
+			name, ok := names[win.PlayerID]
+			if !ok {
+				return hands.Hand{}, UnknownPlayerError(fmt.Sprintf("player id %d in pot %d", win.PlayerID, pot.Number))
+			}
+			h.Summary.Winners = append(h.Summary.Winners, hands.Winner{PlayerName: name, Amount: win.WinAmount, Board: boardNum})
+		}
+	}
+
+	return h, nil
+}
+
+func streetIndex(s hands.Street) int {
+	switch s {
+	case hands.Flop:
+		return 1
+	case hands.Turn:
+		return 2
+	case hands.River:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func dealCards(board *hands.CommunityCards, street hands.Street, cards []hands.Card) {
+	switch street {
+	case hands.Flop:
+		copy(board.Flop[:], cards)
+	case hands.Turn:
+		board.Turn = cards[0]
+	case hands.River:
+		board.River = cards[0]
+	}
+}
+
+// knownCards returns cards without the empty entries of cards that were not dealt.
+func knownCards(cards ...hands.Card) []hands.Card {
+	var known []hands.Card
+	for _, c := range cards {
+		if c != "" {
+			known = append(known, c)
+		}
+	}
+	return known
+}
+
+// Encoder writes hands as a stream of OHH documents, one per line.
+type Encoder struct {
+	enc *json.Encoder
+}
+
+// NewEncoder returns an Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{enc: json.NewEncoder(w)}
+}
+
+// Encode converts h and writes it as a single OHH document.
+func (e *Encoder) Encode(h hands.Hand) error {
+	hh, err := FromHand(h)
+	if err != nil {
+		return err
+	}
+	return e.enc.Encode(Document{OHH: hh})
+}
+
+// Close does nothing, the stream needs no terminator. It does not close the underlying writer.
+func (e *Encoder) Close() error {
+	return nil
+}
+
+// Decoder reads hands from a stream of OHH documents separated by whitespace.
+type Decoder struct {
+	dec *json.Decoder
+}
+
+// NewDecoder returns a Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(r)}
+}
+
+// Decode reads the next OHH document and converts it to a hands.Hand. It returns io.EOF once the stream is
+// exhausted.
+func (d *Decoder) Decode() (hands.Hand, error) {
+	var doc Document
+	if err := d.dec.Decode(&doc); err != nil {
+		return hands.Hand{}, err
+	}
+	return ToHand(doc.OHH)
+}
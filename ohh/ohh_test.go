@@ -0,0 +1,222 @@
+package ohh
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"pokerhud/hands"
+	"reflect"
+	"testing"
+)
+
+// parsedCorpus returns the hands parsed from the PokerStars histories in testdata
+func parsedCorpus(t *testing.T) []hands.Hand {
+	t.Helper()
+	var parsed []hands.Hand
+	result := hands.ExportHandsWithOptions(os.DirFS("testdata"), hands.ExportOptions{
+		OnHand: func(h hands.Hand) error {
+			parsed = append(parsed, h)
+			return nil
+		},
+	})
+
+	if result.FsErr != nil || result.FileErrorCount() != 0 || result.HandErrCount() != 0 {
+		t.Fatalf("failed to parse test corpus: %#v", result)
+	}
+	if len(parsed) != 8 {
+		t.Fatalf("wanted 8 hands in the test corpus but got %d", len(parsed))
+	}
+	return parsed
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, h := range parsedCorpus(t) {
+		t.Run(h.Metadata.ID, func(t *testing.T) {
+			hh, err := FromHand(h)
+			if err != nil {
+				t.Fatalf("unexpected error converting to OHH: %v", err)
+			}
+
+			data, err := json.Marshal(Document{OHH: hh})
+			if err != nil {
+				t.Fatalf("unexpected error marshalling OHH: %v", err)
+			}
+
+			var doc Document
+			if err := json.Unmarshal(data, &doc); err != nil {
+				t.Fatalf("unexpected error unmarshalling OHH: %v", err)
+			}
+
+			got, err := ToHand(doc.OHH)
+			if err != nil {
+				t.Fatalf("unexpected error converting from OHH: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, h) {
+				t.Errorf("wanted %#v\n but got %#v", h, got)
+			}
+		})
+	}
+}
+
+func TestFromHand(t *testing.T) {
+	var cashGame hands.Hand
+	for _, h := range parsedCorpus(t) {
+		if h.Metadata.ID == "257507385322" {
+			cashGame = h
+		}
+	}
+
+	hh, err := FromHand(cashGame)
+	if err != nil {
+		t.Fatalf("unexpected error converting to OHH: %v", err)
+	}
+
+	t.Run("metadata", func(t *testing.T) {
+		if hh.GameNumber != "257507385322" || hh.TableName != "Halley" || hh.StartDateUTC != "2025-08-27T17:30:17Z" {
+			t.Errorf("unexpected metadata %#v", hh)
+		}
+
+		if hh.SmallBlindAmount != 0.01 || hh.BigBlindAmount != 0.02 {
+			t.Errorf("wanted blinds 0.01/0.02 but got %v/%v", hh.SmallBlindAmount, hh.BigBlindAmount)
+		}
+
+		if hh.HeroPlayerID == nil || hh.Players[*hh.HeroPlayerID].Name != "KavarzE" {
+			t.Errorf("wanted hero KavarzE but got %v", hh.HeroPlayerID)
+		}
+	})
+
+	t.Run("preflop actions", func(t *testing.T) {
+		preflop := hh.Rounds[0]
+		want := []Action{
+			{1, 4, ActionDealtCards, 0, false, []hands.Card{"8s", "As"}},
+			{2, 1, ActionPostSB, 0.01, false, nil},
+			{3, 2, ActionPostBB, 0.02, false, nil},
+			{4, 3, ActionFold, 0, false, nil},
+			{5, 4, ActionRaise, 0.06, false, nil},
+			{6, 5, ActionFold, 0, false, nil},
+			{7, 0, ActionCall, 0.06, false, nil},
+			{8, 1, ActionFold, 0, false, nil},
+			{9, 2, ActionFold, 0, false, nil},
+		}
+
+		if preflop.Street != StreetPreflop || !reflect.DeepEqual(preflop.Actions, want) {
+			t.Errorf("wanted %#v but got %#v", want, preflop)
+		}
+	})
+
+	t.Run("streets and pots", func(t *testing.T) {
+		var streets []string
+		for _, r := range hh.Rounds {
+			streets = append(streets, r.Street)
+		}
+
+		wantStreets := []string{StreetPreflop, StreetFlop, StreetTurn}
+		if !reflect.DeepEqual(streets, wantStreets) {
+			t.Errorf("wanted rounds %v but got %v", wantStreets, streets)
+		}
+
+		wantPots := []Pot{{Number: 0, Amount: 0.23, Rake: 0.01, PlayerWins: []PlayerWin{{0, 0.22}}}}
+		if !reflect.DeepEqual(hh.Pots, wantPots) {
+			t.Errorf("wanted pots %#v but got %#v", wantPots, hh.Pots)
+		}
+	})
+}
+
+func TestFromHandReraise(t *testing.T) {
+	var ritEdgeCase hands.Hand
+	for _, h := range parsedCorpus(t) {
+		if h.Metadata.ID == "254626500457" {
+			ritEdgeCase = h
+		}
+	}
+
+	hh, err := FromHand(ritEdgeCase)
+	if err != nil {
+		t.Fatalf("unexpected error converting to OHH: %v", err)
+	}
+
+	// Zutuzutu_90: raises $0.07 to $0.12, KavarzE: raises $0.33 to $0.45, Zutuzutu_90: raises $0.45 to $0.75
+	var raises []float64
+	for _, r := range hh.Rounds {
+		for _, a := range r.Actions {
+			if a.Action == ActionRaise {
+				raises = append(raises, a.Amount)
+			}
+		}
+	}
+
+	want := []float64{0.12, 0.45, 0.75}
+	if !reflect.DeepEqual(raises, want) {
+		t.Errorf("wanted raises to %v but got %v", want, raises)
+	}
+}
+
+func TestEncoderDecoder(t *testing.T) {
+	corpus := parsedCorpus(t)
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	for _, h := range corpus {
+		if err := enc.Encode(h); err != nil {
+			t.Fatalf("unexpected error encoding hand: %v", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("unexpected error closing encoder: %v", err)
+	}
+
+	dec := NewDecoder(&buf)
+	var got []hands.Hand
+	for {
+		h, err := dec.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error decoding hand: %v", err)
+		}
+		got = append(got, h)
+	}
+
+	if !reflect.DeepEqual(got, corpus) {
+		t.Errorf("decoded hands differ from the encoded hands")
+	}
+}
+
+func TestToHandErrors(t *testing.T) {
+	valid := HandHistory{StartDateUTC: "2025-01-19T12:38:55Z", Players: []Player{{ID: 0, Name: "KavarzE"}}}
+
+	tests := []struct {
+		name    string
+		modify  func(hh *HandHistory)
+		wantErr error
+	}{
+		{"unknown action", func(hh *HandHistory) {
+			hh.Rounds = []Round{{Street: StreetPreflop, Actions: []Action{{PlayerID: 0, Action: "Sits Down"}}}}
+		}, errUnknownAction},
+		{"unknown street", func(hh *HandHistory) {
+			hh.Rounds = []Round{{Street: "Fifth Street"}}
+		}, errUnknownStreet},
+		{"unknown player", func(hh *HandHistory) {
+			hh.Rounds = []Round{{Street: StreetPreflop, Actions: []Action{{PlayerID: 7, Action: ActionFold}}}}
+		}, errUnknownPlayer},
+		{"unknown winner", func(hh *HandHistory) {
+			hh.Pots = []Pot{{PlayerWins: []PlayerWin{{PlayerID: 7, WinAmount: 1}}}}
+		}, errUnknownPlayer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hh := valid
+			tt.modify(&hh)
+
+			_, err := ToHand(hh)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("wanted error %v but got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+// Package report summarises the result of an import, per file, as JSON for scheduled jobs to alert on, or as a
+// table for people.
+//
+// # JSON schema
+//
+// The JSON report is versioned by SchemaVersion:
+//
+//	{"schema_version": 1, "error": "", "error_category": "", "totals": <totals>, "files": [<file>, ...],
+//	 "unrecognised_lines": [<line>, ...]}
+//
+// error and error_category describe a failure of the import as a whole, such as an unreadable folder, and are
+// omitted when there was none. Each file has the following fields:
+//
+//	path            string   file name within the hand history folder
+//	hands_parsed    number   hands imported for the first time
+//	hand_errors     number   hands that could not be parsed
+//	duplicates      number   hands that had already been imported
+//	error           string   why the file failed to import, omitted if it didn't
+//	error_category  string   one of the Category constants, omitted if the file didn't fail
+//	duration_ms     number   time spent reading and parsing the file in milliseconds
+//	bytes           number   bytes of hand history read
+//
+// totals holds the sum of every file's hands_parsed, hand_errors, duplicates, duration_ms and bytes, along with
+// files, the number of files, and failed_files, the number of files with an error.
+//
+// unrecognised_lines counts the lines of the imported hands that the parser did not recognise, grouped by pattern
+// and most frequent first. It is omitted when every line was recognised. Each line has the following fields:
+//
+//	pattern  string  the line with player names replaced by <player>, numbers by N and quoted text by "..."
+//	example  string  the first line seen matching the pattern
+//	count    number  lines matching the pattern
+package report
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"pokerhud/hands"
+	"slices"
+	"text/tabwriter"
+	"time"
+)
+
+// SchemaVersion is the version of the JSON report, incremented on incompatible changes.
+const SchemaVersion = 1
+
+// Format names accepted by Report.Write
+const (
+	FormatJSON  = "json"
+	FormatTable = "table"
+)
+
+// Error categories, letting scripts react to a failure without matching on its message
+const (
+	CategoryFailRate    = "fail_rate"    // too many hands in the file failed to parse
+	CategoryNotParsable = "not_parsable" // the file could not be opened or read
+	CategoryFilesystem  = "filesystem"   // the hand history folder could not be read
+	CategoryExport      = "export"       // newly imported hands could not be exported
+	CategoryAborted     = "aborted"      // a strict import stopped at an error
+	CategoryOther       = "other"
+)
+
+// Report summarises an import.
+type Report struct {
+	SchemaVersion int    `json:"schema_version"`
+	Error         string `json:"error,omitempty"`
+	ErrorCategory string `json:"error_category,omitempty"`
+	Totals        Totals `json:"totals"`
+	Files         []File `json:"files"`
+
+	UnrecognisedLines []Line `json:"unrecognised_lines,omitempty"`
+}
+
+// Line counts the unrecognised lines matching a pattern, see hands.LineCount.
+type Line struct {
+	Pattern string `json:"pattern"`
+	Example string `json:"example"`
+	Count   int    `json:"count"`
+}
+
+// File summarises the import of a single file.
+type File struct {
+	Path          string  `json:"path"`
+	HandsParsed   int     `json:"hands_parsed"`
+	HandErrors    int     `json:"hand_errors"`
+	Duplicates    int     `json:"duplicates"`
+	Error         string  `json:"error,omitempty"`
+	ErrorCategory string  `json:"error_category,omitempty"`
+	DurationMS    float64 `json:"duration_ms"`
+	Bytes         int64   `json:"bytes"`
+}
+
+// Totals sums the files of a Report.
+type Totals struct {
+	Files       int     `json:"files"`
+	FailedFiles int     `json:"failed_files"`
+	HandsParsed int     `json:"hands_parsed"`
+	HandErrors  int     `json:"hand_errors"`
+	Duplicates  int     `json:"duplicates"`
+	DurationMS  float64 `json:"duration_ms"`
+	Bytes       int64   `json:"bytes"`
+}
+
+// New returns the Report of an import, with its files ordered by path.
+func New(result hands.ExportResult) Report {
+	r := Report{SchemaVersion: SchemaVersion, Files: make([]File, 0, len(result.FileResults))}
+
+	switch {
+	case result.FsErr != nil:
+		r.Error, r.ErrorCategory = result.FsErr.Error(), CategoryFilesystem
+	case result.OnHandErr != nil:
+		r.Error, r.ErrorCategory = result.OnHandErr.Error(), CategoryExport
+	case result.AbortErr != nil:
+		r.Error, r.ErrorCategory = result.AbortErr.Error(), CategoryAborted
+	}
+
+	for _, fr := range result.FileResults {
+		f := File{
+			Path:        fr.Path,
+			HandsParsed: fr.HandsParsed,
+			HandErrors:  fr.HandErrs,
+			Duplicates:  fr.Duplicates,
+			DurationMS:  milliseconds(fr.Duration),
+			Bytes:       fr.Bytes,
+		}
+		if fr.Err != nil {
+			f.Error, f.ErrorCategory = fr.Err.Error(), Category(fr.Err)
+			r.Totals.FailedFiles++
+		}
+
+		r.Totals.Files++
+		r.Totals.HandsParsed += f.HandsParsed
+		r.Totals.HandErrors += f.HandErrors
+		r.Totals.Duplicates += f.Duplicates
+		r.Totals.DurationMS += f.DurationMS
+		r.Totals.Bytes += f.Bytes
+
+		r.Files = append(r.Files, f)
+	}
+
+	slices.SortFunc(r.Files, func(a, b File) int { return cmp.Compare(a.Path, b.Path) })
+
+	for _, lc := range result.UnrecognisedLines {
+		r.UnrecognisedLines = append(r.UnrecognisedLines, Line{lc.Pattern, lc.Example, lc.Count})
+	}
+	return r
+}
+
+// Category returns the error category of a file error.
+func Category(err error) string {
+	switch {
+	case errors.Is(err, hands.ErrFailRate):
+		return CategoryFailRate
+	case errors.Is(err, hands.ErrFileNotParsable):
+		return CategoryNotParsable
+	case errors.Is(err, hands.ErrAborted):
+		return CategoryAborted
+	default:
+		return CategoryOther
+	}
+}
+
+// Failed reports whether the import, or any file within it, failed.
+func (r Report) Failed() bool {
+	return r.Error != "" || r.Totals.FailedFiles > 0
+}
+
+// Write writes the report to w in format, either FormatJSON or FormatTable.
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		return r.WriteJSON(w)
+	case FormatTable:
+		return r.WriteTable(w)
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+// WriteJSON writes the report to w as an indented JSON document.
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteTable writes the report to w as a table with a row per file followed by the totals, and a second table of
+// any unrecognised lines.
+func (r Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "File\tHands\tHand errs\tDuplicates\tBytes\tDuration\tCategory\tError")
+	for _, f := range r.Files {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.1fms\t%s\t%s\n",
+			f.Path, f.HandsParsed, f.HandErrors, f.Duplicates, f.Bytes, f.DurationMS, f.ErrorCategory, f.Error)
+	}
+	t := r.Totals
+	fmt.Fprintf(tw, "Total (%d files, %d failed)\t%d\t%d\t%d\t%d\t%.1fms\t%s\t%s\n",
+		t.Files, t.FailedFiles, t.HandsParsed, t.HandErrors, t.Duplicates, t.Bytes, t.DurationMS, r.ErrorCategory, r.Error)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.UnrecognisedLines) == 0 {
+		return nil
+	}
+	return WriteUnrecognisedLines(w, r.UnrecognisedLines)
+}
+
+// WriteUnrecognisedLines writes lines to w as a table, preceded by a blank line to separate it from a previous table.
+func WriteUnrecognisedLines(w io.Writer, lines []Line) error {
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Unrecognised lines\tPattern\tExample")
+	for _, l := range lines {
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", l.Count, l.Pattern, l.Example)
+	}
+	return tw.Flush()
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,183 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"pokerhud/hands"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testResult = hands.ExportResult{
+	FileResults: []hands.FileResult{
+		{Path: "zoom.txt", HandsParsed: 120, HandErrs: 1, Duplicates: 3, Duration: 1500 * time.Microsecond, Bytes: 4096},
+		{Path: "broken.txt", Err: hands.FileNotParsableErr("could not open file"), Duration: time.Millisecond},
+		{Path: "failures.txt", HandsParsed: 2, HandErrs: 5, Err: hands.FailRateErr("2 successful, 5 failed"), Bytes: 512},
+	},
+}
+
+func TestNew(t *testing.T) {
+	got := New(testResult)
+
+	want := Report{
+		SchemaVersion: SchemaVersion,
+		Totals: Totals{
+			Files: 3, FailedFiles: 2, HandsParsed: 122, HandErrors: 6, Duplicates: 3, DurationMS: 2.5, Bytes: 4608,
+		},
+		Files: []File{
+			{
+				Path: "broken.txt", Error: testResult.FileResults[1].Err.Error(), ErrorCategory: CategoryNotParsable,
+				DurationMS: 1,
+			},
+			{
+				Path: "failures.txt", HandsParsed: 2, HandErrors: 5, Error: testResult.FileResults[2].Err.Error(),
+				ErrorCategory: CategoryFailRate, Bytes: 512,
+			},
+			{Path: "zoom.txt", HandsParsed: 120, HandErrors: 1, Duplicates: 3, DurationMS: 1.5, Bytes: 4096},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wanted %#v but got %#v", want, got)
+	}
+
+	if !got.Failed() {
+		t.Error("wanted the report to have failed")
+	}
+}
+
+func TestNewImportErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		result       hands.ExportResult
+		wantCategory string
+	}{
+		{"filesystem", hands.ExportResult{FsErr: errors.New("no such directory")}, CategoryFilesystem},
+		{"export", hands.ExportResult{OnHandErr: errors.New("disk full")}, CategoryExport},
+		{"aborted", hands.ExportResult{AbortErr: errors.New("zoom.txt:12: hand 123: action")}, CategoryAborted},
+		{"success", hands.ExportResult{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(tt.result)
+
+			if got.ErrorCategory != tt.wantCategory {
+				t.Errorf("wanted category %q but got %q", tt.wantCategory, got.ErrorCategory)
+			}
+
+			if got.Failed() != (tt.wantCategory != "") {
+				t.Errorf("wanted Failed %v but got %v", tt.wantCategory != "", got.Failed())
+			}
+		})
+	}
+}
+
+func TestCategory(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{hands.FailRateErr("1 successful, 1 failed"), CategoryFailRate},
+		{hands.FileNotParsableErr("could not open file"), CategoryNotParsable},
+		{hands.AbortedErr("zoom.txt:12: hand 123: action"), CategoryAborted},
+		{errors.New("something else"), CategoryOther},
+	}
+
+	for _, tt := range tests {
+		if got := Category(tt.err); got != tt.want {
+			t.Errorf("wanted category %q for %v but got %q", tt.want, tt.err, got)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	want := New(testResult)
+
+	var buf bytes.Buffer
+	if err := want.Write(&buf, FormatJSON); err != nil {
+		t.Fatalf("wanted no error but got %v", err)
+	}
+
+	var got Report
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("wanted valid JSON but got %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wanted %#v but got %#v", want, got)
+	}
+
+	if strings.Contains(buf.String(), `"error": ""`) {
+		t.Errorf("wanted empty errors omitted but got %s", buf.String())
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New(testResult).Write(&buf, FormatTable); err != nil {
+		t.Fatalf("wanted no error but got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("wanted a header, 3 files and totals but got %q", lines)
+	}
+
+	for i, prefix := range []string{"File", "broken.txt", "failures.txt", "zoom.txt", "Total (3 files, 2 failed)"} {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("wanted line %d to start with %q but got %q", i, prefix, lines[i])
+		}
+	}
+
+	if !strings.Contains(lines[2], CategoryFailRate) {
+		t.Errorf("wanted the fail rate category on %q", lines[2])
+	}
+}
+
+func TestUnrecognisedLines(t *testing.T) {
+	result := hands.ExportResult{
+		FileResults: testResult.FileResults,
+		UnrecognisedLines: []hands.LineCount{
+			{Pattern: `<player> said, "..."`, Example: `KavarzE said, "gl"`, Count: 3},
+			{Pattern: "<player> has timed out", Example: "ManeAlhekine has timed out", Count: 1},
+		},
+	}
+	r := New(result)
+
+	want := []Line{
+		{Pattern: `<player> said, "..."`, Example: `KavarzE said, "gl"`, Count: 3},
+		{Pattern: "<player> has timed out", Example: "ManeAlhekine has timed out", Count: 1},
+	}
+	if !reflect.DeepEqual(r.UnrecognisedLines, want) {
+		t.Errorf("wanted %#v but got %#v", want, r.UnrecognisedLines)
+	}
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf, FormatTable); err != nil {
+		t.Fatalf("wanted no error but got %v", err)
+	}
+
+	_, lines, found := strings.Cut(buf.String(), "\n\n")
+	if !found {
+		t.Fatalf("wanted the unrecognised lines after a blank line but got %q", buf.String())
+	}
+
+	wantLines := []string{
+		"Unrecognised lines  Pattern                 Example",
+		`3                   <player> said, "..."    KavarzE said, "gl"`,
+		"1                   <player> has timed out  ManeAlhekine has timed out",
+	}
+	if got := strings.Split(strings.TrimSpace(lines), "\n"); !reflect.DeepEqual(got, wantLines) {
+		t.Errorf("wanted %q but got %q", wantLines, got)
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := New(testResult).Write(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("wanted an error for an unknown format but got nil")
+	}
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"pokerhud/export"
+	"pokerhud/hands"
+	"slices"
+	"time"
+)
+
+// subscriberBuffer is the number of hands an events client may fall behind by before it is disconnected
+const subscriberBuffer = 256
+
+// replayLimit is the number of most recently added hands whose events are replayed to a reconnecting client
+const replayLimit = 1024
+
+// keepAliveInterval is how often an idle events stream is sent a comment, stopping proxies timing it out
+const keepAliveInterval = 15 * time.Second
+
+// publish sends h to every events subscriber, disconnecting those whose buffer is full. s.mu must be held.
+func (s *Server) publish(h hands.Hand) {
+	for ch := range s.subs {
+		select {
+		case ch <- h:
+		default:
+			delete(s.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// arrival identifies a hand in Server.hands by the fields it is ordered by, along with its site.
+type arrival struct {
+	date time.Time
+	key  hands.HandKey
+}
+
+// remember records h as the most recently added hand, forgetting the oldest once replayLimit hands are known.
+// s.mu must be held.
+func (s *Server) remember(h hands.Hand) {
+	a := arrival{date: h.Metadata.Date, key: h.Metadata.Key()}
+	if len(s.recent) < replayLimit {
+		s.recent = append(s.recent, a)
+		return
+	}
+	s.recent[s.recentNext] = a
+	s.recentNext = (s.recentNext + 1) % replayLimit
+}
+
+// recentHand returns the hand added back hands ago, 1 being the most recent. s.mu must be held.
+func (s *Server) recentHand(back int) hands.Hand {
+	a := s.recent[(s.recentNext-back+len(s.recent))%len(s.recent)]
+	compare := func(h hands.Hand, a arrival) int {
+		return cmp.Or(h.Metadata.Date.Compare(a.date), cmp.Compare(h.Metadata.ID, a.key.ID))
+	}
+
+	i, _ := slices.BinarySearchFunc(s.hands, a, compare)
+	for ; i < len(s.hands) && compare(s.hands[i], a) == 0; i++ {
+		if s.hands[i].Metadata.Key() == a.key {
+			break
+		}
+	}
+	return s.hands[i]
+}
+
+// replay is what a reconnecting events client is sent before the hands added after it subscribed.
+type replay struct {
+	hands   []hands.Hand // the hands added since the client's last event
+	refresh bool         // the client's last event is no longer known, so it must reload its hands instead
+	latest  string       // the ID of the most recently added hand, the event ID of the refresh event
+}
+
+// subscribe registers a new events subscriber, returning it along with the replay of the hands added since the hand
+// with ID lastEventID. Nothing is replayed when lastEventID is empty.
+func (s *Server) subscribe(lastEventID string) (chan hands.Hand, replay) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var r replay
+	if len(s.recent) > 0 {
+		r.latest = s.recentHand(1).Metadata.ID
+	}
+
+	if lastEventID != "" {
+		r.refresh = true
+		// search from the most recent, which is the hand the client saw if an ID was reused
+		for back := 1; back <= len(s.recent); back++ {
+			if s.recentHand(back).Metadata.ID != lastEventID {
+				continue
+			}
+			for ; back > 1; back-- {
+				r.hands = append(r.hands, s.recentHand(back-1))
+			}
+			r.refresh = false
+			break
+		}
+	}
+
+	ch := make(chan hands.Hand, subscriberBuffer)
+	s.subs[ch] = true
+	return ch, r
+}
+
+// unsubscribe removes ch, unless it was already disconnected by publish.
+func (s *Server) unsubscribe(ch chan hands.Hand) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.subs[ch] {
+		delete(s.subs, ch)
+		close(ch)
+	}
+}
+
+func (s *Server) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	ch, replay := s.subscribe(r.Header.Get("Last-Event-ID"))
+	defer s.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	if replay.refresh {
+		if err := writeEvent(w, replay.latest, "refresh", []byte("{}")); err != nil {
+			return
+		}
+	}
+
+	for _, h := range replay.hands {
+		if err := s.writeHandEvents(w, h); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case h, ok := <-ch:
+			if !ok {
+				return // fell too far behind, the client catches up by reconnecting
+			}
+			if err := s.writeHandEvents(w, h); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeHandEvents writes the hand and stats events of h to w.
+func (s *Server) writeHandEvents(w io.Writer, h hands.Hand) error {
+	data, err := export.MarshalHand(h)
+	if err != nil {
+		return err
+	}
+	if err := writeEvent(w, h.Metadata.ID, "hand", data); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	players := make([]playerJSON, 0, len(h.Players))
+	for _, p := range h.Players {
+		ps, _ := s.stats.Player(p.Username)
+		players = append(players, toPlayerJSON(ps))
+	}
+	s.mu.RUnlock()
+
+	data, err = json.Marshal(players)
+	if err != nil {
+		return err
+	}
+	return writeEvent(w, h.Metadata.ID, "stats", data)
+}
+
+// writeEvent writes a single Server-Sent Event. data must not contain newlines, which holds for encoded JSON.
+func writeEvent(w io.Writer, id, event string, data []byte) error {
+	_, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", id, event, data)
+	return err
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type event struct {
+	id, name, data string
+}
+
+// readEvent reads the next event from an events stream, skipping comments
+func readEvent(t *testing.T, r *bufio.Reader) event {
+	t.Helper()
+	var e event
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("wanted an event but got %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "" && e.name != "":
+			return e
+		case strings.HasPrefix(line, "id: "):
+			e.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			e.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+// connect opens an events stream to ts, sending lastEventID if it is set
+func connect(t *testing.T, ts *httptest.Server, lastEventID string) *bufio.Reader {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("wanted to connect but got %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("wanted an event stream but got %q", ct)
+	}
+	return bufio.NewReader(resp.Body)
+}
+
+func TestEvents(t *testing.T) {
+	s := newTestServer()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	t.Run("new hands are streamed with the stats of their players", func(t *testing.T) {
+		r := connect(t, ts, "")
+		s.Add(testHand("5", "Donati", 3*time.Hour, "fish", "hero"))
+
+		e := readEvent(t, r)
+		var h struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal([]byte(e.data), &h); err != nil || e.name != "hand" || e.id != "5" || h.ID != "5" {
+			t.Errorf("wanted a hand event for hand 5 but got %#v", e)
+		}
+
+		e = readEvent(t, r)
+		var players []playerJSON
+		if err := json.Unmarshal([]byte(e.data), &players); err != nil || e.name != "stats" || e.id != "5" {
+			t.Fatalf("wanted a stats event for hand 5 but got %#v", e)
+		}
+		if len(players) != 2 || players[0].Player != "fish" || players[0].Hands != 2 || players[1].Hands != 5 {
+			t.Errorf("wanted the current stats of fish and hero but got %#v", players)
+		}
+	})
+
+	t.Run("reconnecting replays the hands added since the last event", func(t *testing.T) {
+		r := connect(t, ts, "2")
+
+		var ids []string
+		for range 4 {
+			e := readEvent(t, r)
+			if e.name == "hand" {
+				ids = append(ids, e.id)
+			}
+		}
+
+		// newTestServer adds hands 3, 1, 2 and 4 in that order, so 4 and then 5 were added after 2
+		if strings.Join(ids, ",") != "4,5" {
+			t.Errorf("wanted hands 4 and 5 replayed but got %v", ids)
+		}
+	})
+
+	t.Run("reconnecting after an unknown event asks for a refresh", func(t *testing.T) {
+		r := connect(t, ts, "unknown")
+
+		if e := readEvent(t, r); e.name != "refresh" || e.id != "5" || e.data != "{}" {
+			t.Errorf("wanted a refresh event at hand 5 but got %#v", e)
+		}
+	})
+}
+
+func TestReplayIsBounded(t *testing.T) {
+	s := New()
+	for i := range replayLimit + 2 {
+		s.Add(testHand(strconv.Itoa(i), "Halley", time.Duration(i)*time.Minute, "hero", "villain"))
+	}
+	last := strconv.Itoa(replayLimit + 1)
+
+	cases := []struct {
+		lastEventID string
+		wantHands   int
+		wantRefresh bool
+	}{
+		{"1", 0, true}, // the hands added after it are no longer all known
+		{"2", replayLimit - 1, false},
+		{strconv.Itoa(replayLimit), 1, false},
+		{last, 0, false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.lastEventID, func(t *testing.T) {
+			ch, replay := s.subscribe(tt.lastEventID)
+			defer s.unsubscribe(ch)
+
+			if len(replay.hands) != tt.wantHands || replay.refresh != tt.wantRefresh || replay.latest != last {
+				t.Fatalf("wanted %d hands and refresh %v at hand %s but got %d hands and refresh %v at hand %s",
+					tt.wantHands, tt.wantRefresh, last, len(replay.hands), replay.refresh, replay.latest)
+			}
+			if tt.wantHands > 0 && replay.hands[len(replay.hands)-1].Metadata.ID != last {
+				t.Errorf("wanted the replay to end with hand %s but got %s", last, replay.hands[len(replay.hands)-1].Metadata.ID)
+			}
+		})
+	}
+
+	if len(s.recent) != replayLimit {
+		t.Errorf("wanted %d hands remembered for replaying but got %d", replayLimit, len(s.recent))
+	}
+}
+
+func TestSlowSubscriberIsDisconnected(t *testing.T) {
+	s := New()
+	ch, replay := s.subscribe("unknown")
+	if len(replay.hands) != 0 || !replay.refresh {
+		t.Errorf("wanted a refresh rather than hands for an unknown ID but got %d hands", len(replay.hands))
+	}
+
+	for i := range subscriberBuffer + 1 {
+		s.Add(testHand(strconv.Itoa(i), "Halley", time.Duration(i)*time.Minute, "hero", "villain"))
+	}
+
+	n := 0
+	for range ch {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("wanted %d buffered hands before being disconnected but got %d", subscriberBuffer, n)
+	}
+
+	s.unsubscribe(ch) // must not close ch a second time
+}
@@ -0,0 +1,375 @@
+// Package server serves parsed hands and player statistics over a local HTTP JSON API, for overlays and dashboards
+// that don't link this module.
+//
+// # Endpoints
+//
+// Every endpoint responds with JSON. Errors are reported with a 4xx or 5xx status and a body of
+// {"error": "<message>"}.
+//
+//	GET /api/hands                hands, most recent first: {"total": n, "hands": [<hand>, ...]}
+//	GET /api/hands/{id}           a single hand
+//	GET /api/players              statistics of every player, most hands first: [<player>, ...]
+//	GET /api/players/{name}       statistics of a single player
+//	GET /api/sessions             sessions of a player, most recent first: [<session>, ...]
+//	GET /api/events               Server-Sent Events stream of hands as they are added
+//
+// /api/hands accepts the filters player, site, table, from and to, dates in RFC 3339, along with limit, 100 by
+// default and at most 1000, and offset for paging. total counts every hand matching the filters. /api/hands/{id}
+// accepts site, for IDs shared by hands from different sites. /api/players accepts min_hands. /api/sessions accepts
+// player, the hero of the most hands by default, and gap, the longest break within a session as a Go duration such
+// as "45m".
+//
+// A <hand> follows the JSON hand schema of package export. A <player> is:
+//
+//	{"player", "hands", "vpip", "pfr", "three_bet", "af", "net"}
+//
+// where vpip, pfr and three_bet are percentages. A <session> is:
+//
+//	{"player", "start", "end", "duration_seconds", "hands", "tables", "net"}
+//
+// # Events
+//
+// /api/events streams two events for every hand added to the server after the client connects, both with the
+// hand's ID as their event ID. A "hand" event holds the <hand>, and a "stats" event holds the current statistics
+// of the players dealt into it as [<player>, ...]. A client reconnecting with a Last-Event-ID header first receives
+// the events of every hand added since that hand, if it is one of the most recently added. Otherwise it first
+// receives a "refresh" event, with the ID of the latest hand and a data of {}, and should reload the hands it shows
+// from /api/hands. Clients that fall too far behind are disconnected, and catch up by reconnecting.
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"pokerhud/export"
+	"pokerhud/hands"
+	"pokerhud/stats"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Limits on the number of hands returned by /api/hands
+const (
+	defaultHandsLimit = 100
+	maxHandsLimit     = 1000
+)
+
+var errBadParam = errors.New("invalid query parameter")
+
+func badParamErr(name, value string) error {
+	return fmt.Errorf("%w: %s=%q", errBadParam, name, value)
+}
+
+// Server is an http.Handler serving the hands added to it. It is safe for concurrent use, hands may be added
+// while requests are served.
+type Server struct {
+	mu         sync.RWMutex
+	hands      []hands.Hand // ordered by date
+	recent     []arrival    // ring of the most recently added hands, for replaying events
+	recentNext int          // position in recent of the next hand added once it is full
+	keys       map[hands.HandKey]bool
+	stats      *stats.Stats
+	subs       map[chan hands.Hand]bool
+	mux        *http.ServeMux
+}
+
+// New returns a Server without any hands.
+func New() *Server {
+	s := &Server{
+		keys:  map[hands.HandKey]bool{},
+		stats: stats.New(),
+		subs:  map[chan hands.Hand]bool{},
+		mux:   http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /api/hands", s.listHands)
+	s.mux.HandleFunc("GET /api/hands/{id}", s.getHand)
+	s.mux.HandleFunc("GET /api/players", s.listPlayers)
+	s.mux.HandleFunc("GET /api/players/{name}", s.getPlayer)
+	s.mux.HandleFunc("GET /api/sessions", s.listSessions)
+	s.mux.HandleFunc("GET /api/events", s.events)
+	return s
+}
+
+// Add adds h to the server unless it has already been added, reporting whether it was new. New hands are sent to
+// every client of /api/events.
+func (s *Server) Add(h hands.Hand) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := h.Metadata.Key()
+	if s.keys[key] {
+		return false
+	}
+	s.keys[key] = true
+	s.stats.Add(h)
+
+	i, _ := slices.BinarySearchFunc(s.hands, h, compareHands)
+	s.hands = slices.Insert(s.hands, i, h)
+	s.remember(h)
+	s.publish(h)
+	return true
+}
+
+func compareHands(a, b hands.Hand) int {
+	return cmp.Or(a.Metadata.Date.Compare(b.Metadata.Date), cmp.Compare(a.Metadata.ID, b.Metadata.ID))
+}
+
+// ServeHTTP serves the API.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handFilter selects the hands listed by /api/hands.
+type handFilter struct {
+	player, site, table string
+	from, to            time.Time
+}
+
+func (f handFilter) match(h hands.Hand) bool {
+	switch {
+	case f.site != "" && h.Metadata.Site != f.site:
+		return false
+	case f.table != "" && h.Metadata.Table != f.table:
+		return false
+	case !f.from.IsZero() && h.Metadata.Date.Before(f.from):
+		return false
+	case !f.to.IsZero() && h.Metadata.Date.After(f.to):
+		return false
+	case f.player != "":
+		return slices.ContainsFunc(h.Players, func(p hands.Player) bool { return p.Username == f.player })
+	}
+	return true
+}
+
+func (s *Server) listHands(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := handFilter{player: q.Get("player"), site: q.Get("site"), table: q.Get("table")}
+
+	var err error
+	if filter.from, err = timeParam(q.Get("from"), "from"); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if filter.to, err = timeParam(q.Get("to"), "to"); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	limit, err := intParam(q.Get("limit"), "limit", defaultHandsLimit)
+	if err != nil || limit < 1 || limit > maxHandsLimit {
+		writeError(w, http.StatusBadRequest, badParamErr("limit", q.Get("limit")))
+		return
+	}
+	offset, err := intParam(q.Get("offset"), "offset", 0)
+	if err != nil || offset < 0 {
+		writeError(w, http.StatusBadRequest, badParamErr("offset", q.Get("offset")))
+		return
+	}
+
+	s.mu.RLock()
+	total := 0
+	page := []json.RawMessage{}
+	for _, h := range slices.Backward(s.hands) {
+		if !filter.match(h) {
+			continue
+		}
+		total++
+		if total <= offset || len(page) == limit {
+			continue
+		}
+
+		data, err := export.MarshalHand(h)
+		if err != nil {
+			s.mu.RUnlock()
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		page = append(page, data)
+	}
+	s.mu.RUnlock()
+
+	writeJSON(w, http.StatusOK, struct {
+		Total int               `json:"total"`
+		Hands []json.RawMessage `json:"hands"`
+	}{total, page})
+}
+
+func (s *Server) getHand(w http.ResponseWriter, r *http.Request) {
+	id, site := r.PathValue("id"), r.URL.Query().Get("site")
+
+	s.mu.RLock()
+	i := slices.IndexFunc(s.hands, func(h hands.Hand) bool {
+		return h.Metadata.ID == id && (site == "" || h.Metadata.Site == site)
+	})
+	var h hands.Hand
+	if i >= 0 {
+		h = s.hands[i]
+	}
+	s.mu.RUnlock()
+
+	if i < 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("hand %s was not found", id))
+		return
+	}
+
+	data, err := export.MarshalHand(h)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, json.RawMessage(data))
+}
+
+// playerJSON is the API representation of a player's statistics.
+type playerJSON struct {
+	Player   string  `json:"player"`
+	Hands    int     `json:"hands"`
+	VPIP     float64 `json:"vpip"`
+	PFR      float64 `json:"pfr"`
+	ThreeBet float64 `json:"three_bet"`
+	AF       float64 `json:"af"`
+	Net      float64 `json:"net"`
+}
+
+func toPlayerJSON(ps stats.PlayerStats) playerJSON {
+	return playerJSON{ps.Player, ps.Hands, ps.VPIP(), ps.PFR(), ps.ThreeBet(), ps.AF(), ps.Net}
+}
+
+func (s *Server) listPlayers(w http.ResponseWriter, r *http.Request) {
+	minHands, err := intParam(r.URL.Query().Get("min_hands"), "min_hands", 1)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.mu.RLock()
+	players := []playerJSON{}
+	for _, ps := range s.stats.Players() {
+		if ps.Hands >= minHands {
+			players = append(players, toPlayerJSON(ps))
+		}
+	}
+	s.mu.RUnlock()
+
+	writeJSON(w, http.StatusOK, players)
+}
+
+func (s *Server) getPlayer(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	s.mu.RLock()
+	ps, ok := s.stats.Player(name)
+	s.mu.RUnlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("player %s was not found", name))
+		return
+	}
+	writeJSON(w, http.StatusOK, toPlayerJSON(ps))
+}
+
+// sessionJSON is the API representation of a session.
+type sessionJSON struct {
+	Player          string   `json:"player"`
+	Start           string   `json:"start"`
+	End             string   `json:"end"`
+	DurationSeconds float64  `json:"duration_seconds"`
+	Hands           int      `json:"hands"`
+	Tables          []string `json:"tables"`
+	Net             float64  `json:"net"`
+}
+
+func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var gap time.Duration
+	if v := q.Get("gap"); v != "" {
+		var err error
+		if gap, err = time.ParseDuration(v); err != nil || gap <= 0 {
+			writeError(w, http.StatusBadRequest, badParamErr("gap", v))
+			return
+		}
+	}
+
+	s.mu.RLock()
+	player := q.Get("player")
+	if player == "" {
+		player = s.hero()
+	}
+	sessions := stats.Sessions(s.hands, player, gap)
+	s.mu.RUnlock()
+
+	out := make([]sessionJSON, 0, len(sessions))
+	for _, ss := range slices.Backward(sessions) {
+		tables := ss.Tables
+		if tables == nil {
+			tables = []string{}
+		}
+		out = append(out, sessionJSON{
+			Player:          ss.Player,
+			Start:           ss.Start.UTC().Format(time.RFC3339),
+			End:             ss.End.UTC().Format(time.RFC3339),
+			DurationSeconds: ss.Duration().Seconds(),
+			Hands:           ss.Hands,
+			Tables:          tables,
+			Net:             ss.Net,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// hero returns the player whose hole cards were dealt to the owner of the most hands. s.mu must be held.
+func (s *Server) hero() string {
+	counts := map[string]int{}
+	hero := ""
+	for _, h := range s.hands {
+		name := h.Metadata.Hero
+		if name == "" {
+			continue
+		}
+		counts[name]++
+		if counts[name] > counts[hero] || (counts[name] == counts[hero] && name < hero) {
+			hero = name
+		}
+	}
+	return hero
+}
+
+func timeParam(value, name string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, badParamErr(name, value)
+	}
+	return t, nil
+}
+
+func intParam(value, name string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, badParamErr(name, value)
+	}
+	return n, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
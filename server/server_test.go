@@ -0,0 +1,197 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pokerhud/hands"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2025, 1, 29, 16, 0, 0, 0, time.UTC)
+
+// testHand returns a heads up hand in which winner takes the blinds from loser
+func testHand(id, table string, offset time.Duration, winner, loser string) hands.Hand {
+	return hands.Hand{
+		Metadata: hands.Metadata{
+			ID: id, Date: start.Add(offset), ButtonSeat: 1, Site: hands.SitePokerStars, Table: table, Hero: "hero",
+		},
+		Players: []hands.Player{{Username: winner, Seat: 1, ChipCount: 5}, {Username: loser, Seat: 2, ChipCount: 5}},
+		Actions: []hands.Action{
+			{Order: 0, PlayerName: winner, Street: hands.Preflop, ActionType: hands.ActionPost, Amount: 0.02},
+			{Order: 1, PlayerName: loser, Street: hands.Preflop, ActionType: hands.ActionPost, Amount: 0.05},
+			{Order: 2, PlayerName: winner, Street: hands.Preflop, ActionType: hands.ActionRaise, Amount: 0.10},
+			{Order: 3, PlayerName: loser, Street: hands.Preflop, ActionType: hands.ActionFold},
+		},
+		Summary: hands.Summary{Pot: 0.10, Winners: []hands.Winner{{PlayerName: winner, Amount: 0.10}}},
+	}
+}
+
+func newTestServer() *Server {
+	s := New()
+	s.Add(testHand("3", "Halley", 10*time.Minute, "villain", "hero"))
+	s.Add(testHand("1", "Halley", 0, "hero", "villain"))
+	s.Add(testHand("2", "Donati", 5*time.Minute, "hero", "fish"))
+	s.Add(testHand("4", "Halley", 2*time.Hour, "hero", "villain"))
+	return s
+}
+
+// get requests path from s, decoding the JSON response into v
+func get(t *testing.T, s *Server, path string, v any) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("wanted a JSON content type but got %q", ct)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("wanted a JSON response from %s but got %q: %v", path, rec.Body.String(), err)
+	}
+	return rec.Code
+}
+
+func TestAdd(t *testing.T) {
+	s := New()
+	h := testHand("1", "Halley", 0, "hero", "villain")
+
+	if !s.Add(h) {
+		t.Error("wanted the first add of a hand to be new")
+	}
+	if s.Add(h) {
+		t.Error("wanted the second add of a hand to be ignored")
+	}
+}
+
+func TestListHands(t *testing.T) {
+	s := newTestServer()
+
+	tests := []struct {
+		path      string
+		wantTotal int
+		wantIDs   []string
+	}{
+		{"/api/hands", 4, []string{"4", "3", "2", "1"}},
+		{"/api/hands?player=fish", 1, []string{"2"}},
+		{"/api/hands?table=Halley&limit=2", 3, []string{"4", "3"}},
+		{"/api/hands?table=Halley&limit=2&offset=2", 3, []string{"1"}},
+		{"/api/hands?from=2025-01-29T16:05:00Z&to=2025-01-29T16:10:00Z", 2, []string{"3", "2"}},
+		{"/api/hands?site=Other", 0, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			var got struct {
+				Total int `json:"total"`
+				Hands []struct {
+					ID string `json:"id"`
+				} `json:"hands"`
+			}
+
+			if code := get(t, s, tt.path, &got); code != http.StatusOK {
+				t.Fatalf("wanted status 200 but got %d", code)
+			}
+
+			ids := []string{}
+			for _, h := range got.Hands {
+				ids = append(ids, h.ID)
+			}
+			if got.Total != tt.wantTotal || !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("wanted total %d and hands %v but got %d and %v", tt.wantTotal, tt.wantIDs, got.Total, ids)
+			}
+		})
+	}
+}
+
+func TestBadRequests(t *testing.T) {
+	s := newTestServer()
+
+	for _, path := range []string{
+		"/api/hands?limit=0",
+		"/api/hands?limit=5000",
+		"/api/hands?offset=-1",
+		"/api/hands?from=yesterday",
+		"/api/players?min_hands=many",
+		"/api/sessions?gap=long",
+	} {
+		t.Run(path, func(t *testing.T) {
+			var got map[string]string
+			if code := get(t, s, path, &got); code != http.StatusBadRequest {
+				t.Errorf("wanted status 400 but got %d", code)
+			}
+			if got["error"] == "" {
+				t.Errorf("wanted an error message but got %v", got)
+			}
+		})
+	}
+}
+
+func TestGetHand(t *testing.T) {
+	s := newTestServer()
+
+	var got map[string]any
+	if code := get(t, s, "/api/hands/2", &got); code != http.StatusOK {
+		t.Fatalf("wanted status 200 but got %d", code)
+	}
+	if got["id"] != "2" || got["site"] != hands.SitePokerStars {
+		t.Errorf("wanted hand 2 but got %v", got)
+	}
+
+	if code := get(t, s, "/api/hands/2?site=Other", &got); code != http.StatusNotFound {
+		t.Errorf("wanted status 404 for a hand from another site but got %d", code)
+	}
+	if code := get(t, s, "/api/hands/99", &got); code != http.StatusNotFound {
+		t.Errorf("wanted status 404 for an unknown hand but got %d", code)
+	}
+}
+
+func TestPlayers(t *testing.T) {
+	s := newTestServer()
+
+	var all []playerJSON
+	get(t, s, "/api/players?min_hands=2", &all)
+	want := []playerJSON{
+		{Player: "hero", Hands: 4, VPIP: 75, PFR: 75, Net: 0.1},
+		{Player: "villain", Hands: 3, VPIP: 100.0 / 3, PFR: 100.0 / 3, Net: -0.05},
+	}
+	if !reflect.DeepEqual(all, want) {
+		t.Errorf("wanted %#v but got %#v", want, all)
+	}
+
+	var one playerJSON
+	if code := get(t, s, "/api/players/fish", &one); code != http.StatusOK || one.Hands != 1 {
+		t.Errorf("wanted fish's statistics but got %d %#v", code, one)
+	}
+
+	var missing map[string]string
+	if code := get(t, s, "/api/players/nobody", &missing); code != http.StatusNotFound {
+		t.Errorf("wanted status 404 for an unknown player but got %d", code)
+	}
+}
+
+func TestSessions(t *testing.T) {
+	s := newTestServer()
+
+	var got []sessionJSON
+	get(t, s, "/api/sessions", &got)
+	want := []sessionJSON{
+		{
+			Player: "hero", Start: "2025-01-29T18:00:00Z", End: "2025-01-29T18:00:00Z", Hands: 1,
+			Tables: []string{"Halley"}, Net: 0.05,
+		},
+		{
+			Player: "hero", Start: "2025-01-29T16:00:00Z", End: "2025-01-29T16:10:00Z", DurationSeconds: 600, Hands: 3,
+			Tables: []string{"Halley", "Donati"}, Net: 0.05,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wanted %#v but got %#v", want, got)
+	}
+
+	get(t, s, "/api/sessions?player=villain&gap=3h", &got)
+	if len(got) != 1 || got[0].Hands != 3 {
+		t.Errorf("wanted a single session of 3 hands for villain but got %#v", got)
+	}
+}
@@ -0,0 +1,60 @@
+package stats
+
+import (
+	"math"
+	"pokerhud/hands"
+	"slices"
+	"time"
+)
+
+// DefaultSessionGap is the longest break between two hands of the same session used by Sessions when gap is zero.
+const DefaultSessionGap = 30 * time.Minute
+
+// Session is an unbroken stretch of play by a single player, across any number of tables.
+type Session struct {
+	Player string
+	Start  time.Time // start of the first hand
+	End    time.Time // start of the last hand
+	Hands  int
+	Tables []string // tables played, in the order they were first seen
+	Net    float64  // chips won less chips invested
+}
+
+// Duration returns the time between the start of the session's first and last hands.
+func (s Session) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Sessions splits the hands player was dealt into sessions, starting a new session whenever more than gap passes
+// between two hands. hs must be ordered by date.
+func Sessions(hs []hands.Hand, player string, gap time.Duration) []Session {
+	if gap <= 0 {
+		gap = DefaultSessionGap
+	}
+
+	var sessions []Session
+	var current *Session
+	for _, h := range hs {
+		if !dealtIn(h, player) {
+			continue
+		}
+
+		date := h.Metadata.Date
+		if current == nil || date.Sub(current.End) > gap {
+			sessions = append(sessions, Session{Player: player, Start: date})
+			current = &sessions[len(sessions)-1]
+		}
+
+		current.End = date
+		current.Hands++
+		if table := h.Metadata.Table; table != "" && !slices.Contains(current.Tables, table) {
+			current.Tables = append(current.Tables, table)
+		}
+		current.Net = math.Round((current.Net+handNet(h)[player])*100) / 100
+	}
+	return sessions
+}
+
+func dealtIn(h hands.Hand, player string) bool {
+	return slices.ContainsFunc(h.Players, func(p hands.Player) bool { return p.Username == player })
+}
@@ -0,0 +1,66 @@
+package stats
+
+import (
+	"pokerhud/hands"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// handAt returns threeBetHand played at table at start plus offset
+func handAt(table string, start time.Time, offset time.Duration) hands.Hand {
+	h := threeBetHand
+	h.Metadata.Date = start.Add(offset)
+	h.Metadata.Table = table
+	return h
+}
+
+func TestSessions(t *testing.T) {
+	start := time.Date(2025, 1, 29, 16, 0, 0, 0, time.UTC)
+	hs := []hands.Hand{
+		handAt("Halley", start, 0),
+		handAt("Donati", start, 10*time.Minute),
+		{Metadata: hands.Metadata{Date: start.Add(15 * time.Minute)}, Players: []hands.Player{{Username: "A"}}},
+		handAt("Halley", start, 40*time.Minute),
+		handAt("Halley", start, 3*time.Hour),
+	}
+
+	tests := []struct {
+		name   string
+		player string
+		gap    time.Duration
+		want   []Session
+	}{
+		{
+			name:   "default gap splits after a long break",
+			player: "D",
+			want: []Session{
+				{Player: "D", Start: start, End: start.Add(40 * time.Minute), Hands: 3, Tables: []string{"Halley", "Donati"}, Net: 6.15},
+				{Player: "D", Start: start.Add(3 * time.Hour), End: start.Add(3 * time.Hour), Hands: 1, Tables: []string{"Halley"}, Net: 2.05},
+			},
+		},
+		{
+			name:   "shorter gap splits between tables",
+			player: "C",
+			gap:    20 * time.Minute,
+			want: []Session{
+				{Player: "C", Start: start, End: start.Add(10 * time.Minute), Hands: 2, Tables: []string{"Halley", "Donati"}, Net: -3.9},
+				{Player: "C", Start: start.Add(40 * time.Minute), End: start.Add(40 * time.Minute), Hands: 1, Tables: []string{"Halley"}, Net: -1.95},
+				{Player: "C", Start: start.Add(3 * time.Hour), End: start.Add(3 * time.Hour), Hands: 1, Tables: []string{"Halley"}, Net: -1.95},
+			},
+		},
+		{
+			name:   "player never dealt in",
+			player: "E",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sessions(hs, tt.player, tt.gap)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wanted %#v but got %#v", tt.want, got)
+			}
+		})
+	}
+}
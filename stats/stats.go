@@ -0,0 +1,179 @@
+// Package stats aggregates per-player statistics, such as VPIP and PFR, over parsed hands.
+package stats
+
+import (
+	"cmp"
+	"math"
+	"pokerhud/hands"
+	"slices"
+)
+
+// PlayerStats holds the counts a player's statistics are derived from.
+type PlayerStats struct {
+	Player string
+	Hands  int
+
+	VPIPHands int // hands the player voluntarily put money in preflop
+	PFRHands  int // hands the player raised preflop
+
+	ThreeBetChances int // hands the player acted preflop facing a single raise
+	ThreeBets       int // hands the player re-raised a single raise preflop
+
+	// postflop actions, used for the aggression factor
+	Bets   int
+	Raises int
+	Calls  int
+
+	Net float64 // chips won less chips invested
+}
+
+// VPIP returns the percentage of hands the player voluntarily put money in the pot preflop.
+func (s PlayerStats) VPIP() float64 {
+	return percent(s.VPIPHands, s.Hands)
+}
+
+// PFR returns the percentage of hands the player raised preflop.
+func (s PlayerStats) PFR() float64 {
+	return percent(s.PFRHands, s.Hands)
+}
+
+// ThreeBet returns the percentage of chances to re-raise a single preflop raise the player took.
+func (s PlayerStats) ThreeBet() float64 {
+	return percent(s.ThreeBets, s.ThreeBetChances)
+}
+
+// AF returns the postflop aggression factor, (bets + raises) / calls. A player who never called has an AF of
+// their bets and raises.
+func (s PlayerStats) AF() float64 {
+	if s.Calls == 0 {
+		return float64(s.Bets + s.Raises)
+	}
+	return float64(s.Bets+s.Raises) / float64(s.Calls)
+}
+
+func percent(n, of int) float64 {
+	if of == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(of)
+}
+
+// Stats aggregates PlayerStats over every hand added to it. It is not safe for concurrent use.
+type Stats struct {
+	players map[string]*PlayerStats
+}
+
+// New returns an empty Stats.
+func New() *Stats {
+	return &Stats{players: map[string]*PlayerStats{}}
+}
+
+// Add updates the statistics of every player dealt into h.
+func (s *Stats) Add(h hands.Hand) {
+	for _, p := range h.Players {
+		s.player(p.Username).Hands++
+	}
+
+	vpip := map[string]bool{}
+	pfr := map[string]bool{}
+	threeBetChance := map[string]bool{}
+	threeBet := map[string]bool{}
+	raises := 0
+
+	for _, a := range h.Actions {
+		ps := s.player(a.PlayerName)
+
+		if a.Street != hands.Preflop {
+			switch a.ActionType {
+			case hands.ActionBet:
+				ps.Bets++
+			case hands.ActionRaise:
+				ps.Raises++
+			case hands.ActionCall:
+				ps.Calls++
+			}
+			continue
+		}
+
+		if a.ActionType == hands.ActionPost {
+			continue
+		}
+
+		if raises == 1 {
+			threeBetChance[a.PlayerName] = true
+		}
+
+		switch a.ActionType {
+		case hands.ActionCall:
+			vpip[a.PlayerName] = true
+		case hands.ActionBet, hands.ActionRaise:
+			vpip[a.PlayerName] = true
+			pfr[a.PlayerName] = true
+			if raises == 1 {
+				threeBet[a.PlayerName] = true
+			}
+			raises++
+		}
+	}
+
+	for name := range vpip {
+		s.player(name).VPIPHands++
+	}
+	for name := range pfr {
+		s.player(name).PFRHands++
+	}
+	for name := range threeBetChance {
+		s.player(name).ThreeBetChances++
+	}
+	for name := range threeBet {
+		s.player(name).ThreeBets++
+	}
+
+	for name, amount := range handNet(h) {
+		ps := s.player(name)
+		ps.Net = math.Round((ps.Net+amount)*100) / 100
+	}
+}
+
+// handNet returns the chips each player who invested in or won h came away with, less what they invested.
+func handNet(h hands.Hand) map[string]float64 {
+	net := map[string]float64{}
+	for name, invested := range h.Invested() {
+		net[name] -= invested
+	}
+	for _, w := range h.Summary.Winners {
+		net[w.PlayerName] += w.Amount
+	}
+	return net
+}
+
+func (s *Stats) player(name string) *PlayerStats {
+	ps, ok := s.players[name]
+	if !ok {
+		ps = &PlayerStats{Player: name}
+		s.players[name] = ps
+	}
+	return ps
+}
+
+// Player returns the statistics of the named player, and whether they were seen in any hand.
+func (s *Stats) Player(name string) (PlayerStats, bool) {
+	ps, ok := s.players[name]
+	if !ok {
+		return PlayerStats{Player: name}, false
+	}
+	return *ps, true
+}
+
+// Players returns the statistics of every player, most hands played first.
+func (s *Stats) Players() []PlayerStats {
+	players := make([]PlayerStats, 0, len(s.players))
+	for _, ps := range s.players {
+		players = append(players, *ps)
+	}
+
+	slices.SortFunc(players, func(a, b PlayerStats) int {
+		return cmp.Or(cmp.Compare(b.Hands, a.Hands), cmp.Compare(a.Player, b.Player))
+	})
+	return players
+}
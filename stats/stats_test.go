@@ -0,0 +1,98 @@
+package stats
+
+import (
+	"pokerhud/hands"
+	"reflect"
+	"testing"
+)
+
+func action(player string, street hands.Street, actionType hands.ActionType, amount float64) hands.Action {
+	return hands.Action{PlayerName: player, Street: street, ActionType: actionType, Amount: amount}
+}
+
+// threeBetHand is a hand where D opens, C 3-bets from the big blind and D wins on the turn
+var threeBetHand = hands.Hand{
+	Players: []hands.Player{
+		{Username: "A", Seat: 1},
+		{Username: "B", Seat: 2},
+		{Username: "C", Seat: 3},
+		{Username: "D", Seat: 4},
+	},
+	Actions: []hands.Action{
+		action("B", hands.Preflop, hands.ActionPost, 0.02),
+		action("C", hands.Preflop, hands.ActionPost, 0.05),
+		action("D", hands.Preflop, hands.ActionRaise, 0.10),
+		action("A", hands.Preflop, hands.ActionCall, 0.15),
+		action("B", hands.Preflop, hands.ActionFold, 0),
+		action("C", hands.Preflop, hands.ActionRaise, 0.30),
+		action("D", hands.Preflop, hands.ActionCall, 0.30),
+		action("A", hands.Preflop, hands.ActionFold, 0),
+		action("C", hands.Flop, hands.ActionBet, 0.50),
+		action("D", hands.Flop, hands.ActionRaise, 1.00),
+		action("C", hands.Flop, hands.ActionCall, 1.00),
+		action("C", hands.Turn, hands.ActionCheck, 0),
+		action("D", hands.Turn, hands.ActionBet, 2),
+		action("C", hands.Turn, hands.ActionFold, 0),
+	},
+	Summary: hands.Summary{
+		Pot:     4.07,
+		Rake:    0.07,
+		Winners: []hands.Winner{{PlayerName: "D", Amount: 4, Board: 1}},
+	},
+}
+
+func TestStatsAdd(t *testing.T) {
+	s := New()
+	s.Add(threeBetHand)
+
+	want := map[string]PlayerStats{
+		"A": {Player: "A", Hands: 1, VPIPHands: 1, ThreeBetChances: 1, Net: -0.15},
+		"B": {Player: "B", Hands: 1, ThreeBetChances: 1, Net: -0.02},
+		"C": {Player: "C", Hands: 1, VPIPHands: 1, PFRHands: 1, ThreeBetChances: 1, ThreeBets: 1, Bets: 1, Calls: 1, Net: -1.95},
+		"D": {Player: "D", Hands: 1, VPIPHands: 1, PFRHands: 1, Bets: 1, Raises: 1, Net: 2.05},
+	}
+
+	for name, w := range want {
+		got, ok := s.Player(name)
+		if !ok {
+			t.Fatalf("wanted stats for %s but there were none", name)
+		}
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("wanted %#v but got %#v", w, got)
+		}
+	}
+
+	if _, ok := s.Player("E"); ok {
+		t.Error("wanted no stats for a player who wasn't dealt in")
+	}
+}
+
+func TestPlayerStatsRates(t *testing.T) {
+	ps := PlayerStats{Hands: 4, VPIPHands: 2, PFRHands: 1, ThreeBetChances: 2, ThreeBets: 1, Bets: 3, Raises: 1, Calls: 2}
+
+	got := []float64{ps.VPIP(), ps.PFR(), ps.ThreeBet(), ps.AF()}
+	want := []float64{50, 25, 50, 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wanted VPIP, PFR, 3bet and AF %v but got %v", want, got)
+	}
+
+	if empty := (PlayerStats{}); empty.VPIP() != 0 || empty.ThreeBet() != 0 || empty.AF() != 0 {
+		t.Errorf("wanted zero rates without any hands but got %v %v %v", empty.VPIP(), empty.ThreeBet(), empty.AF())
+	}
+}
+
+func TestStatsPlayers(t *testing.T) {
+	s := New()
+	s.Add(threeBetHand)
+	s.Add(hands.Hand{Players: []hands.Player{{Username: "C"}, {Username: "B"}}})
+
+	var order []string
+	for _, ps := range s.Players() {
+		order = append(order, ps.Player)
+	}
+
+	want := []string{"B", "C", "A", "D"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("wanted players ordered %v but got %v", want, order)
+	}
+}